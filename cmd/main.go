@@ -1,14 +1,36 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"marketplace/internal/cards"
+	"marketplace/internal/categories"
+	"marketplace/internal/chat"
+	"marketplace/internal/conversations"
 	"marketplace/internal/datastore"
+	chd "marketplace/internal/handlers/categories"
+	cthd "marketplace/internal/handlers/chat"
+	cvhd "marketplace/internal/handlers/conversations"
 	ihd "marketplace/internal/handlers/images"
+	nhd "marketplace/internal/handlers/notifications"
+	ohd "marketplace/internal/handlers/offers"
+	ordhd "marketplace/internal/handlers/orders"
+	rhd "marketplace/internal/handlers/reviews"
+	shd "marketplace/internal/handlers/searches"
+	thd "marketplace/internal/handlers/tags"
 	uhd "marketplace/internal/handlers/user"
 	"marketplace/internal/images"
 	"marketplace/internal/middleware"
+	"marketplace/internal/notifications"
+	"marketplace/internal/offers"
+	"marketplace/internal/orders"
+	"marketplace/internal/payments"
+	"marketplace/internal/reviews"
+	"marketplace/internal/searches"
+	"marketplace/internal/sessions"
+	"marketplace/internal/tags"
+	"marketplace/internal/token"
 	"marketplace/internal/user"
 	"net/http"
 	"os"
@@ -22,12 +44,71 @@ func main() {
 		log.Fatalf("error while connecting to the database: %v", err)
 	}
 
+	keySet, err := token.LoadKeySet()
+	if err != nil {
+		log.Fatalf("error while loading the JWT signing keys: %v", err)
+	}
+	if keySet == nil {
+		log.Fatalf("the JWT signing key is not configured: set JWT_PRIVATE_KEY_FILE or JWT_PRIVATE_KEY")
+	}
+	token.SetKeySet(keySet)
+
 	usr := user.NewDBRepo(dtb)
-	cards := cards.NewDBRepo(dtb)
+	crd := cards.NewDBRepo(dtb)
+	ctg := categories.NewDBRepo(dtb)
+	srch := searches.NewDBRepo(dtb)
+	ssn := sessions.NewDBRepo(dtb)
 	userHandler := &uhd.UserHandler{
-		UserRepo:  usr,
-		CardsRepo: cards,
+		UserRepo:       usr,
+		CardsRepo:      crd,
+		CategoriesRepo: ctg,
+		SearchesRepo:   srch,
+		SessionsRepo:   ssn,
+	}
+	searchesHandler := &shd.SearchesHandler{
+		SearchesRepo:   srch,
+		CategoriesRepo: ctg,
+	}
+	notificationsHandler := &nhd.NotificationsHandler{
+		NotificationsRepo: notifications.NewDBRepo(dtb),
+	}
+	cnv := conversations.NewDBRepo(dtb)
+	hub := chat.NewMemoryHub()
+	conversationsHandler := &cvhd.ConversationsHandler{
+		ConversationsRepo: cnv,
+		Hub:               hub,
+	}
+	chatHandler := &cthd.ChatHandler{
+		Hub:               hub,
+		ConversationsRepo: cnv,
 	}
+	ofr := offers.NewDBRepo(dtb)
+	offersHandler := &ohd.OffersHandler{
+		OffersRepo: ofr,
+	}
+	paymentProvider, err := payments.NewProviderFromEnv()
+	if err != nil {
+		log.Fatalf("error while configuring the payment provider: %v", err)
+	}
+	ordersRepo := orders.NewDBRepo(dtb)
+	ordersHandler := &ordhd.OrdersHandler{
+		OrdersRepo: ordersRepo,
+		Payments:   paymentProvider,
+	}
+	reviewsHandler := &rhd.ReviewsHandler{
+		ReviewsRepo: reviews.NewDBRepo(dtb),
+	}
+	categoriesHandler := &chd.CategoriesHandler{
+		CategoriesRepo: ctg,
+	}
+	tagsHandler := &thd.TagsHandler{
+		TagsRepo: tags.NewDBRepo(dtb),
+	}
+
+	go crd.RunExpirySweeper(context.Background(), cards.ExpirySweepInterval)
+	go ofr.RunExpirySweeper(context.Background(), offers.ExpirySweepInterval)
+	go ordersRepo.RunExpirySweeper(context.Background(), orders.ExpirySweepInterval)
+	go ordersHandler.RunRefundSweeper(context.Background(), orders.RefundSweepInterval)
 
 	images := images.NewDBRepo(dtb)
 	imagesHandler := &ihd.ImagesHandler{
@@ -37,8 +118,63 @@ func main() {
 	rtr := mux.NewRouter()
 	rtr.HandleFunc("/sign-in", userHandler.SignIn).Methods("POST")
 	rtr.HandleFunc("/sign-up", userHandler.SignUp).Methods("POST")
-	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(userHandler.PostACard, dtb, true)).Methods("POST")
-	rtr.HandleFunc("/get-cards", middleware.RequireAuth(userHandler.GetCards, dtb, false)).Methods("GET")
+	rtr.HandleFunc("/token/refresh", userHandler.RefreshToken).Methods("POST")
+	rtr.HandleFunc("/sign-out", userHandler.SignOut).Methods("POST")
+	rtr.HandleFunc("/.well-known/jwks.json", userHandler.GetJWKS).Methods("GET")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(userHandler.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(userHandler.GetCards, ssn, false)).Methods("GET")
+	rtr.HandleFunc("/cards/stream", middleware.RequireAuth(userHandler.StreamCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(userHandler.GetCard, ssn, false)).Methods("GET")
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(userHandler.EditCard, ssn, true)).Methods("PATCH")
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(userHandler.DeleteCard, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc(cardPath+"/status", middleware.RequireAuth(userHandler.ChangeStatus, ssn, true)).Methods("PATCH")
+	rtr.HandleFunc(cardPath+"/renew", middleware.RequireAuth(userHandler.RenewCard, ssn, true)).Methods("POST")
+	rtr.HandleFunc(cardPath+"/favorite", middleware.RequireAuth(userHandler.AddFavorite, ssn, true)).Methods("POST")
+	rtr.HandleFunc(cardPath+"/favorite", middleware.RequireAuth(userHandler.RemoveFavorite, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc(cardPath+"/messages", middleware.RequireAuth(conversationsHandler.StartConversation, ssn, true)).Methods("POST")
+	rtr.HandleFunc(cardPath+"/offers", middleware.RequireAuth(offersHandler.MakeOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc(cardPath+"/offers", middleware.RequireAuth(offersHandler.GetCardOffers, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/me/offers", middleware.RequireAuth(offersHandler.GetUserOffers, ssn, true)).Methods("GET")
+	offerPath := fmt.Sprintf("/offers/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(offerPath+"/accept", middleware.RequireAuth(offersHandler.AcceptOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc(offerPath+"/reject", middleware.RequireAuth(offersHandler.RejectOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc(offerPath+"/counter", middleware.RequireAuth(offersHandler.CounterOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/orders", middleware.RequireAuth(ordersHandler.CreateOrder, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/me/orders", middleware.RequireAuth(ordersHandler.GetUserOrders, ssn, true)).Methods("GET")
+	orderPath := fmt.Sprintf("/orders/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(orderPath, middleware.RequireAuth(ordersHandler.GetOrder, ssn, true)).Methods("GET")
+	rtr.HandleFunc(orderPath+"/ship", middleware.RequireAuth(ordersHandler.ShipOrder, ssn, true)).Methods("POST")
+	rtr.HandleFunc(orderPath+"/complete", middleware.RequireAuth(ordersHandler.CompleteOrder, ssn, true)).Methods("POST")
+	rtr.HandleFunc(orderPath+"/cancel", middleware.RequireAuth(ordersHandler.CancelOrder, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/payments/webhook", ordersHandler.PaymentWebhook).Methods("POST")
+	rtr.HandleFunc("/users/{username}", userHandler.GetProfile).Methods("GET")
+	rtr.HandleFunc("/me/profile", middleware.RequireAuth(userHandler.UpdateProfile, ssn, true)).Methods("PATCH")
+	rtr.HandleFunc("/users/{username}/follow", middleware.RequireAuth(userHandler.Follow, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/users/{username}/follow", middleware.RequireAuth(userHandler.Unfollow, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc("/users/{username}/followers", userHandler.GetFollowers).Methods("GET")
+	rtr.HandleFunc("/users/{username}/following", userHandler.GetFollowing).Methods("GET")
+	rtr.HandleFunc("/me/feed", middleware.RequireAuth(userHandler.GetFollowingFeed, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/users/{username}/reviews", reviewsHandler.GetReviews).Methods("GET")
+	rtr.HandleFunc("/users/{username}/reviews", middleware.RequireAuth(reviewsHandler.AddReview, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/me/sessions", middleware.RequireAuth(userHandler.GetSessions, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/me/sessions", middleware.RequireAuth(userHandler.RevokeAllSessions, ssn, true)).Methods("DELETE")
+	sessionPath := fmt.Sprintf("/me/sessions/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(sessionPath, middleware.RequireAuth(userHandler.RevokeSession, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc("/me/favorites", middleware.RequireAuth(userHandler.GetFavorites, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/me/searches", middleware.RequireAuth(searchesHandler.SaveSearch, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/me/searches", middleware.RequireAuth(searchesHandler.GetSearches, ssn, true)).Methods("GET")
+	searchPath := fmt.Sprintf("/me/searches/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(searchPath, middleware.RequireAuth(searchesHandler.DeleteSearch, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc("/me/notifications", middleware.RequireAuth(notificationsHandler.GetNotifications, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/me/notifications/read", middleware.RequireAuth(notificationsHandler.MarkAllRead, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/me/conversations", middleware.RequireAuth(conversationsHandler.GetConversations, ssn, true)).Methods("GET")
+	conversationPath := fmt.Sprintf("/conversations/{id:%s}/messages", ihd.UUIDRE)
+	rtr.HandleFunc(conversationPath, middleware.RequireAuth(conversationsHandler.GetMessages, ssn, true)).Methods("GET")
+	rtr.HandleFunc(conversationPath, middleware.RequireAuth(conversationsHandler.SendMessage, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/ws/chat", middleware.TokenFromProtocol(middleware.RequireAuth(chatHandler.ServeWS, ssn, true))).Methods("GET")
+	rtr.HandleFunc("/categories", categoriesHandler.GetCategories).Methods("GET")
+	rtr.HandleFunc("/tags/popular", tagsHandler.GetPopular).Methods("GET")
 	rtr.HandleFunc("/images/create", imagesHandler.CreateImage).Methods("GET")
 	rtr.HandleFunc("/images", imagesHandler.LoadImage).Methods("POST")
 	path := fmt.Sprintf("/images/{name:image%s\\.jpeg}", ihd.UUIDRE)
@@ -6,5 +6,9 @@ require (
 	github.com/golang-jwt/jwt/v5 v5.2.3
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
+	github.com/gorilla/websocket v1.5.3
 	github.com/lib/pq v1.10.9
+	golang.org/x/crypto v0.40.0
 )
+
+require golang.org/x/sys v0.34.0 // indirect
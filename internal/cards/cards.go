@@ -1,6 +1,10 @@
 package cards
 
+import "time"
+
 type CardInput struct {
+	// ID — идентификатор объявления
+	ID string `json:"id,omitempty"`
 	// Title — заголовок
 	Title string `json:"title"`
 	// Text — текст объявления
@@ -9,9 +13,17 @@ type CardInput struct {
 	ImageURL string `json:"image_url"`
 	// Price — цена
 	Price float64 `json:"price"`
+	// CategoryID — категория
+	CategoryID int `json:"category_id"`
+	// Tags — теги
+	Tags []string `json:"tags,omitempty"`
+	// Status — статус объявления
+	Status string `json:"status,omitempty"`
 }
 
 type CardOutput struct {
+	// ID — идентификатор объявления
+	ID string `json:"id"`
 	// Title — заголовок
 	Title string `json:"title"`
 	// Text — текст объявления
@@ -20,10 +32,30 @@ type CardOutput struct {
 	ImageURL string `json:"image_url"`
 	// Price — цена
 	Price float64 `json:"price"`
+	// CategoryID — категория
+	CategoryID int `json:"category_id"`
+	// Tags — теги
+	Tags []string `json:"tags"`
 	// Username — автор
 	Username string `json:"username"`
+	// SellerRating — средняя оценка автора по отзывам, null если отзывов нет
+	SellerRating *float64 `json:"seller_rating"`
+	// SellerReviewsCount — количество отзывов об авторе
+	SellerReviewsCount int `json:"seller_reviews_count"`
+	// Status — статус объявления
+	Status string `json:"status"`
+	// CreatedAt — дата создания
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt — дата истечения срока жизни объявления
+	ExpiresAt time.Time `json:"expires_at"`
 	// isOwned — признак принадлежности объявления текущему пользователю
 	IsOwned bool `json:"is_owned,omitempty"`
+	// IsFavorite — признак наличия объявления в избранном текущего пользователя
+	IsFavorite *bool `json:"is_favorite,omitempty"`
+	// FavoritesCount — количество пользователей, добавивших объявление в избранное
+	FavoritesCount *int `json:"favorites_count,omitempty"`
+	// publishedXID — транзакция, в которой объявление было опубликовано, ключ курсора потока
+	publishedXID *string
 }
 
 type CardsRepo interface {
@@ -31,4 +63,24 @@ type CardsRepo interface {
 	PostACard(crd *CardInput, userID string) (*CardInput, error)
 	// GetCards получает ленту объявлений
 	GetCards(params *QueryParams) ([]CardOutput, error)
+	// CountCards получает количество объявлений в ленте с учетом фильтров
+	CountCards(params *QueryParams) (int, error)
+	// GetCard получает объявление по его идентификатору
+	GetCard(cardID string, username *string) (*CardOutput, int, error)
+	// CheckAuthor проверяет, что объявление существует и принадлежит пользователю
+	CheckAuthor(cardID, userID string) (int, error)
+	// EditCard изменяет объявление, автором которого является пользователь
+	EditCard(cardID, userID string, upd *CardUpdate, username string) (*CardOutput, int, error)
+	// DeleteCard удаляет объявление, автором которого является пользователь
+	DeleteCard(cardID, userID string) (int, error)
+	// ChangeStatus изменяет статус объявления, автором которого является пользователь
+	ChangeStatus(cardID, userID, status, username string) (*CardOutput, int, error)
+	// RenewCard продлевает срок жизни объявления, автором которого является пользователь
+	RenewCard(cardID, userID, username string) (*CardOutput, int, error)
+	// AddFavorite добавляет объявление в избранное пользователя
+	AddFavorite(cardID, userID string) (int, error)
+	// RemoveFavorite удаляет объявление из избранного пользователя
+	RemoveFavorite(cardID, userID string) (int, error)
+	// StreamCursor получает курсор потока, указывающий на текущий момент времени
+	StreamCursor() (*Cursor, error)
 }
@@ -0,0 +1,100 @@
+package cards
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// cursorTimeLayout — формат даты создания объявления в курсоре
+const cursorTimeLayout string = "2006-01-02T15:04:05.999999"
+
+// Cursor — позиция в ленте объявлений для постраничного вывода по ключу (keyset pagination)
+type Cursor struct {
+	// SortBy — поле сортировки, для которого создан курсор
+	SortBy string `json:"s"`
+	// Key — значение поля сортировки последнего полученного объявления
+	Key string `json:"k"`
+	// ID — идентификатор последнего полученного объявления
+	ID string `json:"id"`
+}
+
+// NewCursor создает курсор, указывающий на объявление
+func NewCursor(card *CardOutput, sortBy string) *Cursor {
+	cursor := &Cursor{SortBy: sortBy, ID: card.ID}
+	switch sortBy {
+	case "price":
+		cursor.Key = strconv.FormatFloat(card.Price, 'f', -1, 64)
+	case SortByPublication:
+		if card.publishedXID != nil {
+			cursor.Key = *card.publishedXID
+		}
+	default:
+		cursor.Key = card.CreatedAt.Format(cursorTimeLayout)
+	}
+	return cursor
+}
+
+// Encode кодирует курсор в непрозрачную строку
+func (cursor *Cursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor декодирует курсор и проверяет, что он создан для того же поля сортировки
+func DecodeCursor(encoded, sortBy string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка: некорректный курсор")
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("ошибка: некорректный курсор")
+	}
+
+	if cursor.SortBy != sortBy {
+		return nil, fmt.Errorf("ошибка: курсор создан для другой сортировки")
+	}
+
+	if _, err := uuid.Parse(cursor.ID); err != nil || !cursor.isValidKey() {
+		return nil, fmt.Errorf("ошибка: некорректный курсор")
+	}
+	return &cursor, nil
+}
+
+// isValidKey проверяет, что значение ключа курсора приводится к типу поля сортировки.
+// Цена должна быть записана так же, как в NewCursor: ParseFloat принимает NaN, Inf и
+// шестнадцатеричную запись, которые не приводятся к numeric
+func (cursor *Cursor) isValidKey() bool {
+	var err error
+	switch cursor.SortBy {
+	case "price":
+		var price float64
+		price, err = strconv.ParseFloat(cursor.Key, 64)
+		if err == nil && (math.IsNaN(price) || math.IsInf(price, 0) || strconv.FormatFloat(price, 'f', -1, 64) != cursor.Key) {
+			return false
+		}
+	case SortByPublication:
+		_, err = strconv.ParseUint(cursor.Key, 10, 64)
+	default:
+		_, err = time.Parse(cursorTimeLayout, cursor.Key)
+	}
+	return err == nil
+}
+
+// keyCast — приведение типа значения ключа курсора в запросе
+func (cursor *Cursor) keyCast() string {
+	switch cursor.SortBy {
+	case "price":
+		return "numeric"
+	case SortByPublication:
+		return "xid8"
+	}
+	return "timestamp"
+}
@@ -0,0 +1,41 @@
+package cards_test
+
+import (
+	"encoding/base64"
+	"marketplace/internal/cards"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TestDecodeCursor тестирует декодирование курсора и отклонение поддельных значений ключа и идентификатора
+func TestDecodeCursor(t *testing.T) {
+	card := &cards.CardOutput{ID: uuid.NewString(), Price: 1234.5, CreatedAt: time.Now()}
+	for _, sortBy := range []string{"price", "created_at"} {
+		encoded := cards.NewCursor(card, sortBy).Encode()
+		if _, err := cards.DecodeCursor(encoded, sortBy); err != nil {
+			t.Errorf("%s: ожидался корректный курсор, но получена ошибка: %v", sortBy, err)
+		}
+	}
+
+	id := uuid.NewString()
+	tests := map[string]struct {
+		json   string
+		sortBy string
+	}{
+		"цена не число": {`{"s":"price","k":"abc","id":"` + id + `"}`, "price"},
+		"цена NaN":      {`{"s":"price","k":"NaN","id":"` + id + `"}`, "price"},
+		"цена в шестнадцатеричной записи": {`{"s":"price","k":"0x1p-2","id":"` + id + `"}`, "price"},
+		"некорректная дата":               {`{"s":"created_at","k":"2024-13-01","id":"` + id + `"}`, "created_at"},
+		"некорректная транзакция":         {`{"s":"published_xid","k":"-1","id":"` + id + `"}`, cards.SortByPublication},
+		"некорректный идентификатор":      {`{"s":"price","k":"100","id":"x"}`, "price"},
+		"без идентификатора":              {`{"s":"price","k":"100"}`, "price"},
+	}
+	for name, tt := range tests {
+		encoded := base64.RawURLEncoding.EncodeToString([]byte(tt.json))
+		if _, err := cards.DecodeCursor(encoded, tt.sortBy); err == nil || err.Error() != "ошибка: некорректный курсор" {
+			t.Errorf("%s: ожидалась ошибка некорректного курсора, но получено: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package cards
+
+import (
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+
+	"github.com/lib/pq"
+)
+
+// foreignKeyViolation — код ошибки PostgreSQL о нарушении ограничения внешнего ключа
+const foreignKeyViolation pq.ErrorCode = "23503"
+
+// DeleteCard удаляет объявление, автором которого является пользователь.
+// Объявление с заказами удалить нельзя: заказы хранят платежи и отзывы о продавце
+func (repo *CardsDBRepository) DeleteCard(cardID, userID string) (int, error) {
+	code, err := repo.CheckAuthor(cardID, userID)
+	if err != nil {
+		return code, err
+	}
+
+	_, err = repo.dtb.Exec("DELETE FROM cards WHERE id = $1 AND user_id = $2;", cardID, userID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return hdr.ConflictCode, fmt.Errorf("по объявлению есть заказы, его можно только архивировать")
+	}
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: удаление объявления: %v", err)
+	}
+	return hdr.OKCode, nil
+}
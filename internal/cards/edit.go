@@ -0,0 +1,107 @@
+package cards
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+	"strings"
+)
+
+// CardUpdate содержит изменяемые поля объявления. Поля, равные nil, не изменяются
+type CardUpdate struct {
+	// Title — заголовок
+	Title *string
+	// Text — текст объявления
+	Text *string
+	// ImageURL — адрес изображения
+	ImageURL *string
+	// Price — цена
+	Price *float64
+	// CategoryID — категория
+	CategoryID *int
+	// Tags — теги, заменяющие текущие теги объявления
+	Tags *[]string
+}
+
+// CheckAuthor проверяет, что объявление существует и принадлежит пользователю
+func (repo *CardsDBRepository) CheckAuthor(cardID, userID string) (int, error) {
+	var authorID string
+	err := repo.dtb.QueryRow("SELECT user_id FROM cards WHERE id = $1;", cardID).Scan(&authorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение автора объявления: %v", err)
+	}
+
+	if authorID != userID {
+		return hdr.ForbiddenCode, fmt.Errorf("объявление принадлежит другому пользователю")
+	}
+	return hdr.OKCode, nil
+}
+
+// EditCard изменяет объявление, автором которого является пользователь
+func (repo *CardsDBRepository) EditCard(cardID, userID string, upd *CardUpdate, username string) (*CardOutput, int, error) {
+	code, err := repo.CheckAuthor(cardID, userID)
+	if err != nil {
+		return nil, code, err
+	}
+
+	var setClauses []string
+	var args []interface{}
+	argPos := 1
+
+	if upd.Title != nil {
+		setClauses = append(setClauses, fmt.Sprintf("title = $%d", argPos))
+		args = append(args, *upd.Title)
+		argPos++
+	}
+	if upd.Text != nil {
+		setClauses = append(setClauses, fmt.Sprintf("card_text = $%d", argPos))
+		args = append(args, *upd.Text)
+		argPos++
+	}
+	if upd.ImageURL != nil {
+		setClauses = append(setClauses, fmt.Sprintf("image_url = $%d", argPos))
+		args = append(args, *upd.ImageURL)
+		argPos++
+	}
+	if upd.Price != nil {
+		setClauses = append(setClauses, fmt.Sprintf("price = $%d", argPos))
+		args = append(args, *upd.Price)
+		argPos++
+	}
+	if upd.CategoryID != nil {
+		setClauses = append(setClauses, fmt.Sprintf("category_id = $%d", argPos))
+		args = append(args, *upd.CategoryID)
+		argPos++
+	}
+
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	if len(setClauses) > 0 {
+		query := fmt.Sprintf("UPDATE cards SET %s WHERE id = $%d AND user_id = $%d;", strings.Join(setClauses, ", "), argPos, argPos+1)
+		args = append(args, cardID, userID)
+		_, err = tx.Exec(query, args...)
+		if err != nil {
+			return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: изменение объявления: %v", err)
+		}
+	}
+
+	if upd.Tags != nil {
+		if err := setTags(tx, cardID, *upd.Tags); err != nil {
+			return nil, hdr.InternalServerErrorCode, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+
+	return repo.GetCard(cardID, &username)
+}
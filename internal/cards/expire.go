@@ -0,0 +1,98 @@
+package cards
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"time"
+)
+
+// ExpirySweepInterval — интервал между проверками истекших объявлений
+const ExpirySweepInterval = 10 * time.Minute
+
+// ArchiveExpired переводит в архив опубликованные объявления с истекшим сроком
+// с причиной архивирования expired, по которой такие объявления можно продлить
+func (repo *CardsDBRepository) ArchiveExpired() (int64, error) {
+	query := `UPDATE cards SET status = $1, archived_reason = $2 WHERE status = $3 AND expires_at <= NOW();`
+	res, err := repo.dtb.Exec(query, StatusArchived, ArchivedExpired, StatusPublished)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса к базе данных: архивирование истекших объявлений: %v", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error while getting the number of archived cards: %v", err)
+	}
+	return count, nil
+}
+
+// RunExpirySweeper периодически архивирует истекшие объявления до отмены контекста
+func (repo *CardsDBRepository) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := repo.ArchiveExpired()
+		if err != nil {
+			log.Printf("error while archiving expired cards: %v\n", err)
+		} else if count > 0 {
+			log.Printf("%d expired cards have been archived\n", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RenewCard продлевает срок жизни объявления, автором которого является пользователь.
+// Объявление, ушедшее в архив по истечении срока, снова публикуется. Объявления,
+// архивированные автором или после продажи, продлить нельзя
+func (repo *CardsDBRepository) RenewCard(cardID, userID, username string) (*CardOutput, int, error) {
+	code, err := repo.CheckAuthor(cardID, userID)
+	if err != nil {
+		return nil, code, err
+	}
+
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	var archivedReason *string
+	query := `SELECT status, archived_reason FROM cards WHERE id = $1 FOR UPDATE;`
+	err = tx.QueryRow(query, cardID).Scan(&status, &archivedReason)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение срока жизни объявления: %v", err)
+	}
+
+	switch {
+	case status == StatusDraft, status == StatusPublished, status == StatusReserved:
+	case status == StatusArchived && archivedReason != nil && *archivedReason == ArchivedExpired:
+		status = StatusPublished
+	default:
+		return nil, hdr.ConflictCode, fmt.Errorf("объявление со статусом %s не может быть продлено", status)
+	}
+
+	query = `UPDATE cards SET status = $1, archived_reason = NULL, expires_at = NOW() + $2 * INTERVAL '1 day' WHERE id = $3;`
+	_, err = tx.Exec(query, status, repo.ttlDays, cardID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: продление объявления: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+
+	return repo.GetCard(cardID, &username)
+}
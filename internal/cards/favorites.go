@@ -0,0 +1,54 @@
+package cards
+
+import (
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// favoriteColumns — столбцы признака избранного и количества добавлений в избранное.
+// Параметр запроса с номером %d — логин текущего пользователя
+const favoriteColumns string = `
+            EXISTS(
+                SELECT 1 FROM favorites f JOIN users fu ON fu.id = f.user_id
+                WHERE f.card_id = c.id AND fu.username = $%d
+            ),
+            (SELECT COUNT(*) FROM favorites f WHERE f.card_id = c.id)`
+
+// setFavorite заполняет признак избранного и количество добавлений в избранное,
+// если запрос выполнен авторизованным пользователем
+func (card *CardOutput) setFavorite(username *string, isFavorite bool, favoritesCount int) {
+	if username == nil {
+		return
+	}
+	card.IsFavorite = &isFavorite
+	card.FavoritesCount = &favoritesCount
+}
+
+// AddFavorite добавляет объявление в избранное пользователя
+func (repo *CardsDBRepository) AddFavorite(cardID, userID string) (int, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM cards WHERE id = $1 AND (status = $2 OR user_id = $3));`
+	err := repo.dtb.QueryRow(query, cardID, StatusPublished, userID).Scan(&exists)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("error while checking if the card exists: %v", err)
+	}
+	if !exists {
+		return hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+
+	query = `INSERT INTO favorites (user_id, card_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	_, err = repo.dtb.Exec(query, userID, cardID)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: добавление в избранное: %v", err)
+	}
+	return hdr.OKCode, nil
+}
+
+// RemoveFavorite удаляет объявление из избранного пользователя
+func (repo *CardsDBRepository) RemoveFavorite(cardID, userID string) (int, error) {
+	_, err := repo.dtb.Exec("DELETE FROM favorites WHERE user_id = $1 AND card_id = $2;", userID, cardID)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: удаление из избранного: %v", err)
+	}
+	return hdr.OKCode, nil
+}
@@ -3,53 +3,119 @@ package cards
 import (
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+// QueryParams — параметры запроса ленты объявлений.
+// Поля с JSON-тегами образуют фильтр, который сохраняется в сохраненных поисках
 type QueryParams struct {
-	PerPage  int
-	Offset   int
-	SortBy   string
-	Order    string
-	PriceMin *float64
-	PriceMax *float64
-	Username *string
+	PerPage  int      `json:"-"`
+	Offset   int      `json:"-"`
+	SortBy   string   `json:"-"`
+	Order    string   `json:"-"`
+	PriceMin *float64 `json:"price_min,omitempty"`
+	PriceMax *float64 `json:"price_max,omitempty"`
+	Username *string  `json:"-"`
+	Status   *string  `json:"-"`
+	// Author — логин автора объявлений
+	Author *string `json:"author,omitempty"`
+	// CreatedAfter — нижняя граница даты создания объявления (включительно)
+	CreatedAfter *time.Time `json:"-"`
+	// CreatedBefore — верхняя граница даты создания объявления (не включительно)
+	CreatedBefore *time.Time `json:"-"`
+	// FavoritesOf — идентификатор пользователя, избранные объявления которого нужно получить
+	FavoritesOf *string `json:"-"`
+	// FollowedBy — идентификатор пользователя, объявления продавцов, на которых он подписан, нужно получить
+	FollowedBy *string `json:"-"`
+	// CategoryID — категория, включая все ее подкатегории
+	CategoryID *int `json:"category,omitempty"`
+	// Tags — теги, которые должны быть у объявления одновременно
+	Tags []string `json:"tags,omitempty"`
+	// AnyTags — теги, хотя бы один из которых должен быть у объявления
+	AnyTags []string `json:"any_tags,omitempty"`
+	// Search — строка полнотекстового поиска по заголовку и тексту объявления
+	Search *string `json:"q,omitempty"`
+	// After — курсор, после которого нужно получить объявления. Если задан, Offset не используется
+	After *Cursor `json:"-"`
 }
 
+// SortByRelevance — сортировка по релевантности полнотекстового поиска
+const SortByRelevance string = "relevance"
+
+// SortByPublication — порядок публикации объявлений, используется только в потоке новых объявлений
+const SortByPublication string = "published_xid"
+
+// searchQuery — поисковый запрос, объединяющий русскую и английскую конфигурации
+const searchQuery string = "(websearch_to_tsquery('russian', $%[1]d) || websearch_to_tsquery('english', $%[1]d))"
+
 // GetCards получает ленту объявлений
 func (repo *CardsDBRepository) GetCards(params *QueryParams) ([]CardOutput, error) {
 	baseQuery := `
         SELECT
+            c.id,
             c.title,
             c.card_text,
             c.image_url,
             c.price,
-            u.username
+            c.category_id,
+            ARRAY(
+                SELECT t.name FROM card_tags ct JOIN tags t ON t.id = ct.tag_id
+                WHERE ct.card_id = c.id ORDER BY t.name
+            ),
+            u.username,%s
+            c.status,
+            c.created_at,
+            c.expires_at,
+            c.published_xid::text,%s
         FROM cards c
         JOIN users u ON u.id = c.user_id
     `
 
-	var whereClauses []string
-	var args []interface{}
-	argPos := 1
-
-	if params.PriceMin != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("c.price >= $%d", argPos))
-		args = append(args, *params.PriceMin)
-		argPos++
+	flt := buildFilter(params)
+	whereClauses, args, argPos := flt.whereClauses, flt.args, flt.argPos
+	if params.After != nil {
+		cmp := "<"
+		if params.Order == "asc" {
+			cmp = ">"
+		}
+		whereClauses = append(whereClauses, fmt.Sprintf("(c.%s, c.id) %s ($%d::%s, $%d::uuid)",
+			params.SortBy, cmp, argPos, params.After.keyCast(), argPos+1))
+		args = append(args, params.After.Key, params.After.ID)
+		argPos += 2
 	}
-	if params.PriceMax != nil {
-		whereClauses = append(whereClauses, fmt.Sprintf("c.price <= $%d", argPos))
-		args = append(args, *params.PriceMax)
-		argPos++
+	if params.SortBy == SortByPublication {
+		// Транзакции с номером меньше xmin текущего снимка завершены, а все последующие публикации
+		// получат номер не меньше xmin, поэтому позже зафиксированное объявление не окажется перед курсором
+		whereClauses = append(whereClauses, "c.published_xid < pg_snapshot_xmin(pg_current_snapshot())")
 	}
 
+	baseQuery = fmt.Sprintf(baseQuery, sellerRatingColumns, fmt.Sprintf(favoriteColumns, argPos))
+	if params.Username != nil {
+		args = append(args, *params.Username)
+	} else {
+		args = append(args, "")
+	}
+	argPos++
+
 	if len(whereClauses) > 0 {
 		baseQuery += " WHERE " + strings.Join(whereClauses, " AND ")
 	}
 
-	baseQuery += fmt.Sprintf(" ORDER BY c.%s %s", params.SortBy, params.Order)
+	if params.SortBy == SortByRelevance && flt.searchPos != 0 {
+		rank := fmt.Sprintf("ts_rank(c.search_vector, "+searchQuery+")", flt.searchPos)
+		baseQuery += fmt.Sprintf(" ORDER BY %s %s, c.created_at DESC, c.id DESC", rank, params.Order)
+	} else {
+		baseQuery += fmt.Sprintf(" ORDER BY c.%s %s, c.id %s", params.SortBy, params.Order, params.Order)
+	}
+
+	offset := params.Offset
+	if params.After != nil {
+		offset = 0
+	}
 	baseQuery += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argPos, argPos+1)
-	args = append(args, params.PerPage, params.Offset)
+	args = append(args, params.PerPage, offset)
 
 	rows, err := repo.dtb.Query(baseQuery, args...)
 	if err != nil {
@@ -60,15 +126,29 @@ func (repo *CardsDBRepository) GetCards(params *QueryParams) ([]CardOutput, erro
 	var cards []CardOutput
 	for rows.Next() {
 		var card CardOutput
+		var isFavorite bool
+		var favoritesCount int
 		if err := rows.Scan(
+			&card.ID,
 			&card.Title,
 			&card.Text,
 			&card.ImageURL,
 			&card.Price,
+			&card.CategoryID,
+			pq.Array(&card.Tags),
 			&card.Username,
+			&card.SellerRating,
+			&card.SellerReviewsCount,
+			&card.Status,
+			&card.CreatedAt,
+			&card.ExpiresAt,
+			&card.publishedXID,
+			&isFavorite,
+			&favoritesCount,
 		); err != nil {
 			return nil, err
 		}
+		card.setFavorite(params.Username, isFavorite, favoritesCount)
 		if params.Username != nil && card.Username == *params.Username {
 			card.IsOwned = true
 		} else {
@@ -82,3 +162,126 @@ func (repo *CardsDBRepository) GetCards(params *QueryParams) ([]CardOutput, erro
 
 	return cards, nil
 }
+
+// CountCards получает количество объявлений в ленте с учетом фильтров, без учета курсора и смещения
+func (repo *CardsDBRepository) CountCards(params *QueryParams) (int, error) {
+	query := `SELECT COUNT(*) FROM cards c JOIN users u ON u.id = c.user_id`
+
+	flt := buildFilter(params)
+	if len(flt.whereClauses) > 0 {
+		query += " WHERE " + strings.Join(flt.whereClauses, " AND ")
+	}
+
+	var total int
+	err := repo.dtb.QueryRow(query, flt.args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса к базе данных: подсчет объявлений: %v", err)
+	}
+	return total, nil
+}
+
+// filter — условия отбора объявлений ленты
+type filter struct {
+	whereClauses []string
+	args         []interface{}
+	// argPos — номер следующего параметра запроса
+	argPos int
+	// searchPos — номер параметра строки поиска, 0 если поиск не задан
+	searchPos int
+}
+
+// buildFilter строит условия отбора объявлений ленты по параметрам запроса
+func buildFilter(params *QueryParams) *filter {
+	var whereClauses []string
+	var args []interface{}
+	argPos := 1
+
+	// Автор видит все свои объявления, остальные — только опубликованные и не истекшие
+	isPublic := fmt.Sprintf("(c.status = '%s' AND c.expires_at > NOW())", StatusPublished)
+	if params.Username != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("(%s OR u.username = $%d)", isPublic, argPos))
+		args = append(args, *params.Username)
+		argPos++
+	} else {
+		whereClauses = append(whereClauses, isPublic)
+	}
+	if params.Status != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("c.status = $%d", argPos))
+		args = append(args, *params.Status)
+		argPos++
+	}
+	if params.PriceMin != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("c.price >= $%d", argPos))
+		args = append(args, *params.PriceMin)
+		argPos++
+	}
+	if params.PriceMax != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("c.price <= $%d", argPos))
+		args = append(args, *params.PriceMax)
+		argPos++
+	}
+	if params.Author != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("u.username = $%d", argPos))
+		args = append(args, *params.Author)
+		argPos++
+	}
+	if params.CreatedAfter != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("c.created_at >= $%d::timestamp", argPos))
+		args = append(args, params.CreatedAfter.UTC().Format(cursorTimeLayout))
+		argPos++
+	}
+	if params.CreatedBefore != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("c.created_at < $%d::timestamp", argPos))
+		args = append(args, params.CreatedBefore.UTC().Format(cursorTimeLayout))
+		argPos++
+	}
+	if params.FavoritesOf != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("c.id IN (SELECT card_id FROM favorites WHERE user_id = $%d)", argPos))
+		args = append(args, *params.FavoritesOf)
+		argPos++
+	}
+	if params.FollowedBy != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("c.user_id IN (SELECT seller_id FROM follows WHERE follower_id = $%d)", argPos))
+		args = append(args, *params.FollowedBy)
+		argPos++
+	}
+	if params.CategoryID != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf(`c.category_id IN (
+            WITH RECURSIVE subtree AS (
+                SELECT id FROM categories WHERE id = $%d
+                UNION ALL
+                SELECT ct.id FROM categories ct JOIN subtree st ON ct.parent_id = st.id
+            )
+            SELECT id FROM subtree
+        )`, argPos))
+		args = append(args, *params.CategoryID)
+		argPos++
+	}
+	if len(params.Tags) > 0 {
+		whereClauses = append(whereClauses, fmt.Sprintf(`c.id IN (
+            SELECT ct.card_id FROM card_tags ct JOIN tags t ON t.id = ct.tag_id
+            WHERE t.name = ANY($%d)
+            GROUP BY ct.card_id
+            HAVING COUNT(DISTINCT t.name) = $%d
+        )`, argPos, argPos+1))
+		args = append(args, pq.Array(params.Tags), len(params.Tags))
+		argPos += 2
+	}
+	if len(params.AnyTags) > 0 {
+		whereClauses = append(whereClauses, fmt.Sprintf(`EXISTS (
+            SELECT 1 FROM card_tags ct JOIN tags t ON t.id = ct.tag_id
+            WHERE ct.card_id = c.id AND t.name = ANY($%d)
+        )`, argPos))
+		args = append(args, pq.Array(params.AnyTags))
+		argPos++
+	}
+	searchPos := 0
+	if params.Search != nil {
+		searchPos = argPos
+		whereClauses = append(whereClauses, fmt.Sprintf("c.search_vector @@ "+searchQuery, searchPos))
+		args = append(args, *params.Search)
+		argPos++
+	}
+
+	return &filter{whereClauses: whereClauses, args: args, argPos: argPos, searchPos: searchPos}
+}
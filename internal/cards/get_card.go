@@ -0,0 +1,76 @@
+package cards
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+
+	"github.com/lib/pq"
+)
+
+// GetCard получает объявление по его идентификатору
+func (repo *CardsDBRepository) GetCard(cardID string, username *string) (*CardOutput, int, error) {
+	query := `
+        SELECT
+            c.id,
+            c.title,
+            c.card_text,
+            c.image_url,
+            c.price,
+            c.category_id,
+            ARRAY(
+                SELECT t.name FROM card_tags ct JOIN tags t ON t.id = ct.tag_id
+                WHERE ct.card_id = c.id ORDER BY t.name
+            ),
+            u.username,%s
+            c.status,
+            c.created_at,
+            c.expires_at,
+            c.expires_at <= NOW(),%s
+        FROM cards c
+        JOIN users u ON u.id = c.user_id
+        WHERE c.id = $1;
+    `
+
+	var viewer string
+	if username != nil {
+		viewer = *username
+	}
+	query = fmt.Sprintf(query, sellerRatingColumns, fmt.Sprintf(favoriteColumns, 2))
+
+	var card CardOutput
+	var isExpired, isFavorite bool
+	var favoritesCount int
+	err := repo.dtb.QueryRow(query, cardID, viewer).Scan(
+		&card.ID,
+		&card.Title,
+		&card.Text,
+		&card.ImageURL,
+		&card.Price,
+		&card.CategoryID,
+		pq.Array(&card.Tags),
+		&card.Username,
+		&card.SellerRating,
+		&card.SellerReviewsCount,
+		&card.Status,
+		&card.CreatedAt,
+		&card.ExpiresAt,
+		&isExpired,
+		&isFavorite,
+		&favoritesCount,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение объявления: %v", err)
+	}
+
+	card.IsOwned = username != nil && card.Username == *username
+	card.setFavorite(username, isFavorite, favoritesCount)
+	if (card.Status != StatusPublished || isExpired) && !card.IsOwned {
+		return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	return &card, hdr.OKCode, nil
+}
@@ -6,12 +6,30 @@ import (
 
 // PostACard создает новое объявление
 func (repo *CardsDBRepository) PostACard(crd *CardInput, userID string) (*CardInput, error) {
-	query := `INSERT INTO cards (title, card_text, image_url, price, user_id)
-	         VALUES ($1, $2, $3, $4, $5);`
+	if crd.Status == "" {
+		crd.Status = StatusPublished
+	}
+
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO cards (title, card_text, image_url, price, user_id, category_id, status, expires_at)
+	         VALUES ($1, $2, $3, $4, $5, $6, $7, NOW() + $8 * INTERVAL '1 day') RETURNING id;`
 
-	_, err := repo.dtb.Exec(query, crd.Title, crd.Text, crd.ImageURL, crd.Price, userID)
+	err = tx.QueryRow(query, crd.Title, crd.Text, crd.ImageURL, crd.Price, userID, crd.CategoryID, crd.Status, repo.ttlDays).Scan(&crd.ID)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка запроса к базе данных: создание объявления: %v", err)
 	}
+
+	if err := setTags(tx, crd.ID, crd.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
 	return crd, nil
 }
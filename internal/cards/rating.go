@@ -0,0 +1,6 @@
+package cards
+
+// sellerRatingColumns — столбцы средней оценки автора объявления и количества отзывов о нем
+const sellerRatingColumns string = `
+            (SELECT ROUND(AVG(r.rating), 2) FROM reviews r WHERE r.seller_id = c.user_id),
+            (SELECT COUNT(*) FROM reviews r WHERE r.seller_id = c.user_id),`
@@ -2,12 +2,35 @@ package cards
 
 import (
 	"database/sql"
+	"log"
+	"os"
+	"strconv"
 )
 
+// defaultTTLDays — срок жизни объявления в днях по умолчанию
+const defaultTTLDays int = 30
+
 type CardsDBRepository struct {
 	dtb *sql.DB
+	// ttlDays — срок жизни объявления в днях
+	ttlDays int
 }
 
 func NewDBRepo(sdb *sql.DB) *CardsDBRepository {
-	return &CardsDBRepository{dtb: sdb}
+	return &CardsDBRepository{dtb: sdb, ttlDays: getTTLDays()}
+}
+
+// getTTLDays получает срок жизни объявления из переменной окружения CARD_TTL_DAYS
+func getTTLDays() int {
+	ttlParam := os.Getenv("CARD_TTL_DAYS")
+	if ttlParam == "" {
+		return defaultTTLDays
+	}
+
+	ttl, err := strconv.Atoi(ttlParam)
+	if err != nil || ttl <= 0 {
+		log.Printf("invalid CARD_TTL_DAYS value %q, using the default value %d\n", ttlParam, defaultTTLDays)
+		return defaultTTLDays
+	}
+	return ttl
 }
@@ -0,0 +1,125 @@
+package cards
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// Статусы объявления
+const (
+	// StatusDraft — черновик, виден только автору
+	StatusDraft string = "draft"
+	// StatusPublished — опубликовано, видно всем
+	StatusPublished string = "published"
+	// StatusReserved — зарезервировано покупателем
+	StatusReserved string = "reserved"
+	// StatusSold — продано
+	StatusSold string = "sold"
+	// StatusArchived — в архиве
+	StatusArchived string = "archived"
+)
+
+// Причины архивирования объявления
+const (
+	// ArchivedExpired — срок жизни объявления истек, объявление можно продлить
+	ArchivedExpired string = "expired"
+	// ArchivedManual — объявление архивировано автором
+	ArchivedManual string = "manual"
+)
+
+// transitions — допустимые переходы между статусами объявления
+var transitions = map[string][]string{
+	StatusDraft:     {StatusPublished, StatusArchived},
+	StatusPublished: {StatusReserved, StatusArchived},
+	StatusReserved:  {StatusPublished, StatusSold, StatusArchived},
+	StatusSold:      {StatusArchived},
+	StatusArchived:  {},
+}
+
+// IsValidStatus проверяет, что статус объявления существует
+func IsValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// CanTransition проверяет, допустим ли переход объявления из статуса from в статус to
+func CanTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// hasActiveOrder проверяет, есть ли по объявлению незавершенный заказ
+func hasActiveOrder(tx *sql.Tx, cardID string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM orders WHERE card_id = $1 AND status IN ('pending', 'paid', 'shipped'));`
+	err := tx.QueryRow(query, cardID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("ошибка запроса к базе данных: проверка заказов по объявлению: %v", err)
+	}
+	return exists, nil
+}
+
+// ChangeStatus изменяет статус объявления, автором которого является пользователь.
+// Пока по зарезервированному объявлению есть незавершенный заказ, его статус изменяется только заказом
+func (repo *CardsDBRepository) ChangeStatus(cardID, userID, status, username string) (*CardOutput, int, error) {
+	if !IsValidStatus(status) {
+		return nil, hdr.BadRequestCode, fmt.Errorf("неизвестный статус объявления: %q", status)
+	}
+
+	code, err := repo.CheckAuthor(cardID, userID)
+	if err != nil {
+		return nil, code, err
+	}
+
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRow("SELECT status FROM cards WHERE id = $1 FOR UPDATE;", cardID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение статуса объявления: %v", err)
+	}
+
+	if !CanTransition(current, status) {
+		return nil, hdr.ConflictCode, fmt.Errorf("недопустимый переход статуса объявления: %s -> %s", current, status)
+	}
+
+	if current == StatusReserved {
+		hasOrder, err := hasActiveOrder(tx, cardID)
+		if err != nil {
+			return nil, hdr.InternalServerErrorCode, err
+		}
+		if hasOrder {
+			return nil, hdr.ConflictCode, fmt.Errorf("по объявлению есть незавершенный заказ, статус изменяется заказом")
+		}
+	}
+
+	var archivedReason *string
+	if status == StatusArchived {
+		reason := ArchivedManual
+		archivedReason = &reason
+	}
+
+	_, err = tx.Exec("UPDATE cards SET status = $1, archived_reason = $2 WHERE id = $3;", status, archivedReason, cardID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: изменение статуса объявления: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+
+	return repo.GetCard(cardID, &username)
+}
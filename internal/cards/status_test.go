@@ -0,0 +1,31 @@
+package cards_test
+
+import (
+	"marketplace/internal/cards"
+	"testing"
+)
+
+// TestCanTransition тестирует допустимые и недопустимые переходы между статусами объявления
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		result   bool
+	}{
+		{cards.StatusDraft, cards.StatusPublished, true},
+		{cards.StatusPublished, cards.StatusReserved, true},
+		{cards.StatusReserved, cards.StatusSold, true},
+		{cards.StatusReserved, cards.StatusPublished, true},
+		{cards.StatusPublished, cards.StatusArchived, true},
+		{cards.StatusDraft, cards.StatusSold, false},
+		{cards.StatusPublished, cards.StatusDraft, false},
+		{cards.StatusSold, cards.StatusPublished, false},
+		{cards.StatusArchived, cards.StatusPublished, false},
+		{"unknown", cards.StatusPublished, false},
+	}
+
+	for _, test := range tests {
+		if got := cards.CanTransition(test.from, test.to); got != test.result {
+			t.Errorf("%s -> %s: ожидалось %t, но получено %t", test.from, test.to, test.result, got)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package cards
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// maxUUID — наибольший идентификатор, используется в курсоре, указывающем на момент времени
+const maxUUID string = "ffffffff-ffff-ffff-ffff-ffffffffffff"
+
+// StreamCursor получает курсор потока, указывающий на текущий момент времени: после него
+// будут только объявления, опубликованные транзакциями, которые еще не завершились
+func (repo *CardsDBRepository) StreamCursor() (*Cursor, error) {
+	var xmin uint64
+	err := repo.dtb.QueryRow("SELECT pg_snapshot_xmin(pg_current_snapshot())::text;").Scan(&xmin)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение текущей транзакции: %v", err)
+	}
+	return &Cursor{SortBy: SortByPublication, Key: strconv.FormatUint(xmin-1, 10), ID: maxUUID}, nil
+}
@@ -0,0 +1,30 @@
+package cards
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// setTags заменяет теги объявления
+func setTags(tx *sql.Tx, cardID string, tags []string) error {
+	_, err := tx.Exec("DELETE FROM card_tags WHERE card_id = $1;", cardID)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к базе данных: удаление тегов объявления: %v", err)
+	}
+
+	for _, tag := range tags {
+		var tagID string
+		query := `INSERT INTO tags (name) VALUES ($1)
+		         ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING id;`
+		err := tx.QueryRow(query, tag).Scan(&tagID)
+		if err != nil {
+			return fmt.Errorf("ошибка запроса к базе данных: создание тега: %v", err)
+		}
+
+		_, err = tx.Exec("INSERT INTO card_tags (card_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;", cardID, tagID)
+		if err != nil {
+			return fmt.Errorf("ошибка запроса к базе данных: добавление тега к объявлению: %v", err)
+		}
+	}
+	return nil
+}
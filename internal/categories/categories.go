@@ -0,0 +1,19 @@
+package categories
+
+type Category struct {
+	// ID — идентификатор категории
+	ID int `json:"id"`
+	// Name — название категории
+	Name string `json:"name"`
+	// ParentID — идентификатор родительской категории
+	ParentID *int `json:"parent_id,omitempty"`
+	// Children — дочерние категории
+	Children []*Category `json:"children,omitempty"`
+}
+
+type CategoriesRepo interface {
+	// GetTree получает дерево категорий
+	GetTree() ([]*Category, error)
+	// Exists проверяет, существует ли категория
+	Exists(categoryID int) (bool, error)
+}
@@ -0,0 +1,52 @@
+package categories
+
+import "fmt"
+
+// GetTree получает дерево категорий
+func (repo *CategoriesDBRepository) GetTree() ([]*Category, error) {
+	rows, err := repo.dtb.Query("SELECT id, name, parent_id FROM categories ORDER BY name;")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение категорий: %v", err)
+	}
+	defer rows.Close()
+
+	var all []*Category
+	byID := make(map[int]*Category)
+	for rows.Next() {
+		var ctg Category
+		if err := rows.Scan(&ctg.ID, &ctg.Name, &ctg.ParentID); err != nil {
+			return nil, err
+		}
+		all = append(all, &ctg)
+		byID[ctg.ID] = &ctg
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	roots := []*Category{}
+	for _, ctg := range all {
+		if ctg.ParentID == nil {
+			roots = append(roots, ctg)
+			continue
+		}
+
+		parent, ok := byID[*ctg.ParentID]
+		if !ok {
+			return nil, fmt.Errorf("родительская категория %d не найдена", *ctg.ParentID)
+		}
+		parent.Children = append(parent.Children, ctg)
+	}
+	return roots, nil
+}
+
+// Exists проверяет, существует ли категория
+func (repo *CategoriesDBRepository) Exists(categoryID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1);`
+	err := repo.dtb.QueryRow(query, categoryID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("error while checking if the category exists: %v", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,13 @@
+package categories
+
+import (
+	"database/sql"
+)
+
+type CategoriesDBRepository struct {
+	dtb *sql.DB
+}
+
+func NewDBRepo(sdb *sql.DB) *CategoriesDBRepository {
+	return &CategoriesDBRepository{dtb: sdb}
+}
@@ -0,0 +1,41 @@
+package chat
+
+import "marketplace/internal/conversations"
+
+// Виды событий чата
+const (
+	// EventMessage — новое сообщение в переписке
+	EventMessage string = "message"
+	// EventTyping — собеседник набирает сообщение
+	EventTyping string = "typing"
+	// EventRead — собеседник прочитал сообщения переписки
+	EventRead string = "read"
+	// EventError — ошибка обработки события, полученного от клиента
+	EventError string = "error"
+)
+
+// Event — событие чата, доставляемое участникам переписки
+type Event struct {
+	// Type — вид события
+	Type string `json:"type"`
+	// ConversationID — переписка
+	ConversationID string `json:"conversation_id,omitempty"`
+	// Username — логин пользователя, вызвавшего событие
+	Username string `json:"username,omitempty"`
+	// Message — новое сообщение (для события message)
+	Message *conversations.Message `json:"message,omitempty"`
+	// Error — описание ошибки (для события error)
+	Error string `json:"error,omitempty"`
+}
+
+// Hub доставляет события чата подключенным пользователям.
+// Реализация в памяти процесса обслуживает один экземпляр сервиса; для нескольких экземпляров
+// Publish может передавать событие через PostgreSQL NOTIFY, а каждый экземпляр — доставлять
+// полученные через LISTEN события своим подписчикам
+type Hub interface {
+	// Subscribe подписывает подключение пользователя на события. Возвращает канал событий
+	// и функцию отписки, которая закрывает канал
+	Subscribe(userID string) (<-chan Event, func())
+	// Publish доставляет событие всем подключениям пользователей
+	Publish(evt Event, userIDs ...string) error
+}
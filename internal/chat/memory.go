@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"log"
+	"sync"
+)
+
+// subscriberBuffer — размер буфера канала событий подписчика
+const subscriberBuffer int = 32
+
+// MemoryHub — концентратор событий чата в памяти процесса
+type MemoryHub struct {
+	mtx         sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+func NewMemoryHub() *MemoryHub {
+	return &MemoryHub{subscribers: make(map[string]map[chan Event]struct{})}
+}
+
+// Subscribe подписывает подключение пользователя на события
+func (hub *MemoryHub) Subscribe(userID string) (<-chan Event, func()) {
+	events := make(chan Event, subscriberBuffer)
+
+	hub.mtx.Lock()
+	if hub.subscribers[userID] == nil {
+		hub.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	hub.subscribers[userID][events] = struct{}{}
+	hub.mtx.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			hub.mtx.Lock()
+			defer hub.mtx.Unlock()
+
+			delete(hub.subscribers[userID], events)
+			if len(hub.subscribers[userID]) == 0 {
+				delete(hub.subscribers, userID)
+			}
+			close(events)
+		})
+	}
+	return events, unsubscribe
+}
+
+// Publish доставляет событие всем подключениям пользователей.
+// Если подписчик не успевает читать события, событие для него отбрасывается
+func (hub *MemoryHub) Publish(evt Event, userIDs ...string) error {
+	hub.mtx.RLock()
+	defer hub.mtx.RUnlock()
+
+	for _, userID := range userIDs {
+		for events := range hub.subscribers[userID] {
+			select {
+			case events <- evt:
+			default:
+				log.Printf("chat event %q for the user %s was dropped: subscriber is too slow\n", evt.Type, userID)
+			}
+		}
+	}
+	return nil
+}
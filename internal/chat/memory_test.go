@@ -0,0 +1,46 @@
+package chat
+
+import "testing"
+
+func TestMemoryHub(t *testing.T) {
+	hub := NewMemoryHub()
+	first, unsubscribeFirst := hub.Subscribe("buyer")
+	second, unsubscribeSecond := hub.Subscribe("buyer")
+	other, unsubscribeOther := hub.Subscribe("other")
+	defer unsubscribeSecond()
+	defer unsubscribeOther()
+
+	evt := Event{Type: EventTyping, ConversationID: "conversation", Username: "seller"}
+	if err := hub.Publish(evt, "buyer", "seller"); err != nil {
+		t.Fatalf("Ошибка публикации события: %v", err)
+	}
+
+	for _, events := range []<-chan Event{first, second} {
+		select {
+		case got := <-events:
+			if got != evt {
+				t.Errorf("Ожидалось событие %+v, но получено %+v", evt, got)
+			}
+		default:
+			t.Errorf("Событие не доставлено подключению пользователя")
+		}
+	}
+
+	select {
+	case got := <-other:
+		t.Errorf("Событие доставлено постороннему пользователю: %+v", got)
+	default:
+	}
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	if _, ok := <-first; ok {
+		t.Errorf("Ожидалось, что канал событий будет закрыт после отписки")
+	}
+	if err := hub.Publish(evt, "buyer"); err != nil {
+		t.Fatalf("Ошибка публикации события после отписки: %v", err)
+	}
+	if got := <-second; got != evt {
+		t.Errorf("Ожидалось событие %+v, но получено %+v", evt, got)
+	}
+}
@@ -0,0 +1,54 @@
+package conversations
+
+import "time"
+
+// Conversation — переписка покупателя и продавца по объявлению
+type Conversation struct {
+	// ID — идентификатор переписки
+	ID string `json:"id"`
+	// CardID — объявление
+	CardID string `json:"card_id"`
+	// CardTitle — заголовок объявления
+	CardTitle string `json:"card_title"`
+	// Buyer — логин покупателя
+	Buyer string `json:"buyer"`
+	// Seller — логин продавца
+	Seller string `json:"seller"`
+	// LastMessage — последнее сообщение
+	LastMessage *Message `json:"last_message,omitempty"`
+	// UnreadCount — количество непрочитанных текущим пользователем сообщений
+	UnreadCount int `json:"unread_count"`
+	// UpdatedAt — дата последнего сообщения
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Message — сообщение в переписке
+type Message struct {
+	// ID — идентификатор сообщения
+	ID string `json:"id"`
+	// ConversationID — переписка
+	ConversationID string `json:"conversation_id"`
+	// Sender — логин отправителя
+	Sender string `json:"sender"`
+	// Text — текст сообщения
+	Text string `json:"text"`
+	// IsRead — признак прочтения получателем
+	IsRead bool `json:"is_read"`
+	// CreatedAt — дата отправки
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ConversationsRepo interface {
+	// StartConversation отправляет сообщение продавцу объявления, создавая переписку при необходимости
+	StartConversation(cardID, buyerID, text string) (*Message, int, error)
+	// SendMessage отправляет сообщение в существующую переписку
+	SendMessage(conversationID, senderID, text string) (*Message, int, error)
+	// GetConversations получает переписки пользователя, начиная с последней активной
+	GetConversations(userID string) ([]Conversation, error)
+	// GetMessages получает сообщения переписки, начиная с самых новых
+	GetMessages(conversationID, userID string, limit, offset int) ([]Message, int, error)
+	// MarkRead отмечает прочитанными сообщения собеседника в переписке
+	MarkRead(conversationID, userID string) (int, error)
+	// GetParticipants получает идентификаторы покупателя и продавца переписки
+	GetParticipants(conversationID string) (string, string, int, error)
+}
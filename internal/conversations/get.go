@@ -0,0 +1,150 @@
+package conversations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// GetConversations получает переписки пользователя с последним сообщением и количеством
+// непрочитанных сообщений, начиная с последней активной
+func (repo *ConversationsDBRepository) GetConversations(userID string) ([]Conversation, error) {
+	query := `
+        SELECT
+            cv.id,
+            cv.card_id,
+            c.title,
+            b.username,
+            s.username,
+            cv.updated_at,
+            (SELECT COUNT(*) FROM messages m WHERE m.conversation_id = cv.id AND m.sender_id <> $1 AND NOT m.is_read),
+            lm.id,
+            lu.username,
+            lm.body,
+            lm.is_read,
+            lm.created_at
+        FROM conversations cv
+        JOIN cards c ON c.id = cv.card_id
+        JOIN users b ON b.id = cv.buyer_id
+        JOIN users s ON s.id = cv.seller_id
+        LEFT JOIN LATERAL (
+            SELECT * FROM messages m WHERE m.conversation_id = cv.id
+            ORDER BY m.created_at DESC, m.id DESC LIMIT 1
+        ) lm ON TRUE
+        LEFT JOIN users lu ON lu.id = lm.sender_id
+        WHERE cv.buyer_id = $1 OR cv.seller_id = $1
+        ORDER BY cv.updated_at DESC, cv.id DESC;
+    `
+
+	rows, err := repo.dtb.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение переписок: %v", err)
+	}
+	defer rows.Close()
+
+	conversations := []Conversation{}
+	for rows.Next() {
+		var cnv Conversation
+		var msgID, msgSender, msgText sql.NullString
+		var msgIsRead sql.NullBool
+		var msgCreatedAt sql.NullTime
+		if err := rows.Scan(&cnv.ID, &cnv.CardID, &cnv.CardTitle, &cnv.Buyer, &cnv.Seller, &cnv.UpdatedAt, &cnv.UnreadCount,
+			&msgID, &msgSender, &msgText, &msgIsRead, &msgCreatedAt); err != nil {
+			return nil, err
+		}
+		if msgID.Valid {
+			cnv.LastMessage = &Message{
+				ID:             msgID.String,
+				ConversationID: cnv.ID,
+				Sender:         msgSender.String,
+				Text:           msgText.String,
+				IsRead:         msgIsRead.Bool,
+				CreatedAt:      msgCreatedAt.Time,
+			}
+		}
+		conversations = append(conversations, cnv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+// GetMessages получает сообщения переписки, начиная с самых новых.
+// Сообщения доступны только участникам переписки
+func (repo *ConversationsDBRepository) GetMessages(conversationID, userID string, limit, offset int) ([]Message, int, error) {
+	code, err := repo.checkParticipant(conversationID, userID)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	query := `
+        SELECT m.id, u.username, m.body, m.is_read, m.created_at
+        FROM messages m
+        JOIN users u ON u.id = m.sender_id
+        WHERE m.conversation_id = $1
+        ORDER BY m.created_at DESC, m.id DESC
+        LIMIT $2 OFFSET $3;
+    `
+
+	rows, err := repo.dtb.Query(query, conversationID, limit, offset)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение сообщений: %v", err)
+	}
+	defer rows.Close()
+
+	messages := []Message{}
+	for rows.Next() {
+		msg := Message{ConversationID: conversationID}
+		if err := rows.Scan(&msg.ID, &msg.Sender, &msg.Text, &msg.IsRead, &msg.CreatedAt); err != nil {
+			return nil, hdr.InternalServerErrorCode, err
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, hdr.InternalServerErrorCode, err
+	}
+	return messages, hdr.OKCode, nil
+}
+
+// MarkRead отмечает прочитанными сообщения собеседника в переписке
+func (repo *ConversationsDBRepository) MarkRead(conversationID, userID string) (int, error) {
+	code, err := repo.checkParticipant(conversationID, userID)
+	if code != hdr.OKCode {
+		return code, err
+	}
+
+	query := `UPDATE messages SET is_read = TRUE WHERE conversation_id = $1 AND sender_id <> $2 AND NOT is_read;`
+	_, err = repo.dtb.Exec(query, conversationID, userID)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: отметка сообщений прочитанными: %v", err)
+	}
+	return hdr.OKCode, nil
+}
+
+// GetParticipants получает идентификаторы покупателя и продавца переписки
+func (repo *ConversationsDBRepository) GetParticipants(conversationID string) (string, string, int, error) {
+	var buyerID, sellerID string
+	query := `SELECT buyer_id, seller_id FROM conversations WHERE id = $1;`
+	err := repo.dtb.QueryRow(query, conversationID).Scan(&buyerID, &sellerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", hdr.NotFoundCode, fmt.Errorf("переписка не существует")
+	}
+	if err != nil {
+		return "", "", hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение переписки: %v", err)
+	}
+	return buyerID, sellerID, hdr.OKCode, nil
+}
+
+// checkParticipant проверяет, что пользователь участвует в переписке
+func (repo *ConversationsDBRepository) checkParticipant(conversationID, userID string) (int, error) {
+	buyerID, sellerID, code, err := repo.GetParticipants(conversationID)
+	if code != hdr.OKCode {
+		return code, err
+	}
+	if userID != buyerID && userID != sellerID {
+		return hdr.ForbiddenCode, fmt.Errorf("переписка принадлежит другим пользователям")
+	}
+	return hdr.OKCode, nil
+}
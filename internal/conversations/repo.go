@@ -0,0 +1,13 @@
+package conversations
+
+import (
+	"database/sql"
+)
+
+type ConversationsDBRepository struct {
+	dtb *sql.DB
+}
+
+func NewDBRepo(sdb *sql.DB) *ConversationsDBRepository {
+	return &ConversationsDBRepository{dtb: sdb}
+}
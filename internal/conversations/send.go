@@ -0,0 +1,110 @@
+package conversations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/cards"
+	hdr "marketplace/internal/handlers"
+)
+
+// StartConversation отправляет сообщение продавцу объявления от имени покупателя.
+// Переписка создается при первом сообщении; новую переписку можно начать только
+// по опубликованному и не истекшему объявлению
+func (repo *ConversationsDBRepository) StartConversation(cardID, buyerID, text string) (*Message, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var sellerID string
+	var isPublic bool
+	query := `SELECT user_id, status = $2 AND expires_at > NOW() FROM cards WHERE id = $1;`
+	err = tx.QueryRow(query, cardID, cards.StatusPublished).Scan(&sellerID, &isPublic)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение объявления: %v", err)
+	}
+
+	if sellerID == buyerID {
+		return nil, hdr.BadRequestCode, fmt.Errorf("ошибка: нельзя написать сообщение по своему объявлению")
+	}
+
+	var conversationID string
+	query = `SELECT id FROM conversations WHERE card_id = $1 AND buyer_id = $2 AND seller_id = $3;`
+	err = tx.QueryRow(query, cardID, buyerID, sellerID).Scan(&conversationID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if !isPublic {
+			return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+		}
+
+		query = `
+            INSERT INTO conversations (card_id, buyer_id, seller_id) VALUES ($1, $2, $3)
+            ON CONFLICT (card_id, buyer_id, seller_id) DO UPDATE SET updated_at = conversations.updated_at
+            RETURNING id;
+        `
+		err = tx.QueryRow(query, cardID, buyerID, sellerID).Scan(&conversationID)
+		if err != nil {
+			return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: создание переписки: %v", err)
+		}
+	case err != nil:
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение переписки: %v", err)
+	}
+
+	msg, err := insertMessage(tx, conversationID, buyerID, text)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return msg, hdr.OKCode, nil
+}
+
+// SendMessage отправляет сообщение в существующую переписку от имени одного из ее участников
+func (repo *ConversationsDBRepository) SendMessage(conversationID, senderID, text string) (*Message, int, error) {
+	code, err := repo.checkParticipant(conversationID, senderID)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	msg, err := insertMessage(tx, conversationID, senderID, text)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return msg, hdr.OKCode, nil
+}
+
+// insertMessage добавляет сообщение в переписку и обновляет дату последнего сообщения переписки
+func insertMessage(tx *sql.Tx, conversationID, senderID, text string) (*Message, error) {
+	msg := &Message{ConversationID: conversationID, Text: text}
+	query := `
+        INSERT INTO messages (conversation_id, sender_id, body) VALUES ($1, $2, $3)
+        RETURNING id, (SELECT username FROM users WHERE id = $2), is_read, created_at;
+    `
+	err := tx.QueryRow(query, conversationID, senderID, text).Scan(&msg.ID, &msg.Sender, &msg.IsRead, &msg.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: добавление сообщения: %v", err)
+	}
+
+	_, err = tx.Exec("UPDATE conversations SET updated_at = $2 WHERE id = $1;", conversationID, msg.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: обновление переписки: %v", err)
+	}
+	return msg, nil
+}
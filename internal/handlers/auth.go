@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+type contextKey string
+
+const (
+	// KeyUser — ключ контекста запроса, по которому хранится авторизованный пользователь
+	KeyUser = contextKey("user")
+)
+
+// AuthUser — пользователь, от имени которого выполнен запрос
+type AuthUser struct {
+	// ID — идентификатор пользователя (утверждение sub токена)
+	ID string
+	// Username — логин пользователя
+	Username string
+	// SessionID — идентификатор сессии, в рамках которой выдан токен
+	SessionID string
+}
+
+// UserFromContext получает авторизованного пользователя из контекста запроса
+func UserFromContext(ctx context.Context) (*AuthUser, bool) {
+	user, ok := ctx.Value(KeyUser).(*AuthUser)
+	return user, ok && user != nil
+}
+
+// GetCurrentUser получает логин и идентификатор пользователя, сохраненного в контексте запроса middleware.RequireAuth.
+// В случае ошибки отправляет сообщение об ошибке и возвращает false
+func GetCurrentUser(wrt http.ResponseWriter, rqt *http.Request) (string, string, bool) {
+	user, ok := UserFromContext(rqt.Context())
+	if !ok {
+		errSend := SendUnauthorized(wrt, "ошибка: пользователь не авторизован")
+		if errSend != nil {
+			log.Printf("error while sending the unauthorized error message: %v\n", errSend)
+		}
+		return "", "", false
+	}
+	return user.Username, user.ID, true
+}
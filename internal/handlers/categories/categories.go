@@ -0,0 +1,9 @@
+package categories
+
+import (
+	"marketplace/internal/categories"
+)
+
+type CategoriesHandler struct {
+	CategoriesRepo categories.CategoriesRepo
+}
@@ -0,0 +1,27 @@
+package categories
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"net/http"
+)
+
+// GetCategories получает дерево категорий
+func (hnd *CategoriesHandler) GetCategories(wrt http.ResponseWriter, rqt *http.Request) {
+	tree, err := hnd.CategoriesRepo.GetTree()
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(tree)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
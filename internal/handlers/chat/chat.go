@@ -0,0 +1,11 @@
+package chat
+
+import (
+	"marketplace/internal/chat"
+	"marketplace/internal/conversations"
+)
+
+type ChatHandler struct {
+	Hub               chat.Hub
+	ConversationsRepo conversations.ConversationsRepo
+}
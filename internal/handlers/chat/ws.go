@@ -0,0 +1,160 @@
+package chat
+
+import (
+	"log"
+	"marketplace/internal/chat"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/middleware"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait — время ожидания записи сообщения клиенту
+	writeWait = 10 * time.Second
+	// pongWait — время ожидания ответа клиента на ping
+	pongWait = 60 * time.Second
+	// pingPeriod — период отправки ping клиенту, должен быть меньше pongWait
+	pingPeriod = pongWait * 9 / 10
+	// maxClientEventSize — максимальный размер события, полученного от клиента, в байтах
+	maxClientEventSize int64 = 4096
+)
+
+// upgrader переключает соединение на протокол WebSocket. Подключения принимаются только
+// с того же источника (Origin), что и сервер. В ответе выбирается подпротокол middleware.TokenProtocol,
+// чтобы браузер принял соединение, в котором токен передан в заголовке Sec-WebSocket-Protocol
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	Subprotocols:    []string{middleware.TokenProtocol},
+}
+
+// ClientEvent — событие, полученное от клиента: typing или read
+type ClientEvent struct {
+	// Type — вид события
+	Type string `json:"type"`
+	// ConversationID — переписка
+	ConversationID string `json:"conversation_id"`
+}
+
+// ServeWS доставляет текущему пользователю события чата по протоколу WebSocket
+// и принимает от него уведомления о наборе и прочтении сообщений
+func (hnd *ChatHandler) ServeWS(wrt http.ResponseWriter, rqt *http.Request) {
+	username, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	conn, err := upgrader.Upgrade(wrt, rqt, nil)
+	if err != nil {
+		log.Printf("error while upgrading the connection to websocket: %v\n", err)
+		return
+	}
+	defer conn.Close()
+
+	events, unsubscribe := hnd.Hub.Subscribe(userID)
+	defer unsubscribe()
+
+	replies := make(chan chat.Event, 1)
+	done := make(chan struct{})
+	go writeEvents(conn, events, replies, done)
+	defer close(done)
+
+	conn.SetReadLimit(maxClientEventSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		var evt ClientEvent
+		if err := conn.ReadJSON(&evt); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("error while reading the websocket event: %v\n", err)
+			}
+			return
+		}
+
+		if errStr := hnd.handleClientEvent(&evt, username, userID); errStr != "" {
+			select {
+			case replies <- chat.Event{Type: chat.EventError, ConversationID: evt.ConversationID, Error: errStr}:
+			default:
+			}
+		}
+	}
+}
+
+// handleClientEvent обрабатывает событие клиента и доставляет его собеседнику.
+// Возвращает описание ошибки или пустую строку
+func (hnd *ChatHandler) handleClientEvent(evt *ClientEvent, username, userID string) string {
+	switch evt.Type {
+	case chat.EventTyping, chat.EventRead:
+	default:
+		return "ошибка: неизвестный вид события"
+	}
+
+	buyerID, sellerID, code, err := hnd.ConversationsRepo.GetParticipants(evt.ConversationID)
+	if code != hdr.OKCode {
+		return err.Error()
+	}
+	if userID != buyerID && userID != sellerID {
+		return "переписка принадлежит другим пользователям"
+	}
+
+	if evt.Type == chat.EventRead {
+		code, err := hnd.ConversationsRepo.MarkRead(evt.ConversationID, userID)
+		if code != hdr.OKCode {
+			return err.Error()
+		}
+	}
+
+	recipientID := buyerID
+	if userID == buyerID {
+		recipientID = sellerID
+	}
+
+	err = hnd.Hub.Publish(chat.Event{Type: evt.Type, ConversationID: evt.ConversationID, Username: username}, recipientID)
+	if err != nil {
+		log.Printf("error while publishing the chat event %q: %v\n", evt.Type, err)
+	}
+	return ""
+}
+
+// writeEvents отправляет клиенту события чата и ответы на его события, а также периодически
+// проверяет соединение с помощью ping. Завершается при закрытии done или канала событий
+func writeEvents(conn *websocket.Conn, events <-chan chat.Event, replies <-chan chat.Event, done <-chan struct{}) {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		var evt chat.Event
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				conn.Close()
+				return
+			}
+			continue
+		case reply := <-replies:
+			evt = reply
+		case event, ok := <-events:
+			if !ok {
+				conn.Close()
+				return
+			}
+			evt = event
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteJSON(evt); err != nil {
+			log.Printf("error while sending the websocket event: %v\n", err)
+			conn.Close()
+			return
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"marketplace/internal/chat"
+	"marketplace/internal/conversations"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/middleware"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeConversations — переписки в памяти: идентификатор переписки -> покупатель и продавец
+type fakeConversations struct {
+	conversations.ConversationsRepo
+	participants map[string][2]string
+	read         []string
+}
+
+func (repo *fakeConversations) GetParticipants(conversationID string) (string, string, int, error) {
+	participants, ok := repo.participants[conversationID]
+	if !ok {
+		return "", "", hdr.NotFoundCode, fmt.Errorf("переписка не существует")
+	}
+	return participants[0], participants[1], hdr.OKCode, nil
+}
+
+func (repo *fakeConversations) MarkRead(conversationID, userID string) (int, error) {
+	repo.read = append(repo.read, conversationID+":"+userID)
+	return hdr.OKCode, nil
+}
+
+// withTestUser сохраняет в контексте запроса пользователя, логин и идентификатор которого совпадают с токеном
+func withTestUser(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if userID := r.Header.Get("Authorization"); userID != "" {
+			user := &hdr.AuthUser{ID: userID, Username: userID}
+			r = r.WithContext(context.WithValue(r.Context(), hdr.KeyUser, user))
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// receiveEvent ожидает событие из канала подписки
+func receiveEvent(t *testing.T, events <-chan chat.Event) chat.Event {
+	t.Helper()
+	select {
+	case evt := <-events:
+		return evt
+	case <-time.After(2 * time.Second):
+		t.Fatal("Событие не доставлено")
+		return chat.Event{}
+	}
+}
+
+// TestServeWS тестирует подключение WebSocket с токеном в заголовке Sec-WebSocket-Protocol
+// и доставку событий клиента собеседнику
+func TestServeWS(t *testing.T) {
+	hub := chat.NewMemoryHub()
+	repo := &fakeConversations{participants: map[string][2]string{
+		"conversation": {"buyer", "seller"},
+		"other":        {"user1", "user3"},
+	}}
+	hnd := &ChatHandler{Hub: hub, ConversationsRepo: repo}
+
+	ts := httptest.NewServer(middleware.TokenFromProtocol(withTestUser(hnd.ServeWS)))
+	t.Cleanup(ts.Close)
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	t.Run("подключение без токена", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получено: %v", http.StatusUnauthorized, err)
+		}
+	})
+
+	dialer := websocket.Dialer{Subprotocols: []string{middleware.TokenProtocol, "buyer"}}
+	conn, resp, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Ошибка подключения: %v", err)
+	}
+	defer conn.Close()
+	if protocol := resp.Header.Get("Sec-WebSocket-Protocol"); protocol != middleware.TokenProtocol {
+		t.Errorf("Ожидался подпротокол %s, но получен: %q", middleware.TokenProtocol, protocol)
+	}
+
+	sellerEvents, unsubscribe := hub.Subscribe("seller")
+	defer unsubscribe()
+
+	for _, eventType := range []string{chat.EventTyping, chat.EventRead} {
+		if err := conn.WriteJSON(ClientEvent{Type: eventType, ConversationID: "conversation"}); err != nil {
+			t.Fatalf("Ошибка отправки события: %v", err)
+		}
+		want := chat.Event{Type: eventType, ConversationID: "conversation", Username: "buyer"}
+		if got := receiveEvent(t, sellerEvents); got != want {
+			t.Errorf("Ожидалось событие %+v, но получено %+v", want, got)
+		}
+	}
+	if len(repo.read) != 1 || repo.read[0] != "conversation:buyer" {
+		t.Errorf("Ожидалась отметка о прочтении переписки покупателем, но получено: %v", repo.read)
+	}
+
+	t.Run("ошибки событий клиента", func(t *testing.T) {
+		for _, evt := range []ClientEvent{
+			{Type: "unknown", ConversationID: "conversation"},
+			{Type: chat.EventTyping, ConversationID: "other"},
+		} {
+			if err := conn.WriteJSON(evt); err != nil {
+				t.Fatalf("Ошибка отправки события: %v", err)
+			}
+
+			conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			var reply chat.Event
+			if err := conn.ReadJSON(&reply); err != nil {
+				t.Fatalf("Ошибка получения ответа: %v", err)
+			}
+			if reply.Type != chat.EventError || reply.ConversationID != evt.ConversationID || reply.Error == "" {
+				t.Errorf("Ожидалось событие ошибки, но получено: %+v", reply)
+			}
+		}
+	})
+
+	t.Run("доставка события клиенту", func(t *testing.T) {
+		evt := chat.Event{Type: chat.EventTyping, ConversationID: "conversation", Username: "seller"}
+		if err := hub.Publish(evt, "buyer"); err != nil {
+			t.Fatalf("Ошибка публикации события: %v", err)
+		}
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var got chat.Event
+		if err := conn.ReadJSON(&got); err != nil {
+			t.Fatalf("Ошибка получения события: %v", err)
+		}
+		if got != evt {
+			t.Errorf("Ожидалось событие %+v, но получено %+v", evt, got)
+		}
+	})
+}
+
+// TestHandleClientEvent тестирует проверку событий клиента
+func TestHandleClientEvent(t *testing.T) {
+	hnd := &ChatHandler{
+		Hub:               chat.NewMemoryHub(),
+		ConversationsRepo: &fakeConversations{participants: map[string][2]string{"conversation": {"buyer", "seller"}}},
+	}
+
+	tests := map[string]struct {
+		evt    ClientEvent
+		userID string
+		want   string
+	}{
+		"набор сообщения покупателем": {ClientEvent{Type: chat.EventTyping, ConversationID: "conversation"}, "buyer", ""},
+		"прочтение продавцом":         {ClientEvent{Type: chat.EventRead, ConversationID: "conversation"}, "seller", ""},
+		"неизвестный вид события":     {ClientEvent{Type: "message", ConversationID: "conversation"}, "buyer", "ошибка: неизвестный вид события"},
+		"несуществующая переписка":    {ClientEvent{Type: chat.EventTyping, ConversationID: "missing"}, "buyer", "переписка не существует"},
+		"чужая переписка":             {ClientEvent{Type: chat.EventRead, ConversationID: "conversation"}, "other", "переписка принадлежит другим пользователям"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := hnd.handleClientEvent(&tt.evt, tt.userID, tt.userID); got != tt.want {
+				t.Errorf("Ожидалась ошибка %q, но получено %q", tt.want, got)
+			}
+		})
+	}
+}
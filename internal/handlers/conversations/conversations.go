@@ -0,0 +1,39 @@
+package conversations
+
+import (
+	"log"
+	"marketplace/internal/chat"
+	"marketplace/internal/conversations"
+	hdr "marketplace/internal/handlers"
+)
+
+type ConversationsHandler struct {
+	ConversationsRepo conversations.ConversationsRepo
+	// Hub доставляет события чата подключенным участникам переписки. Может быть nil
+	Hub chat.Hub
+}
+
+// publish доставляет событие чата участникам переписки, кроме пользователя exceptUserID.
+// Ошибка не влияет на ответ клиенту и только записывается в журнал
+func (hnd *ConversationsHandler) publish(evt chat.Event, exceptUserID string) {
+	if hnd.Hub == nil {
+		return
+	}
+
+	buyerID, sellerID, code, err := hnd.ConversationsRepo.GetParticipants(evt.ConversationID)
+	if code != hdr.OKCode {
+		log.Printf("error while getting participants of the conversation %s: %v\n", evt.ConversationID, err)
+		return
+	}
+
+	var userIDs []string
+	for _, userID := range []string{buyerID, sellerID} {
+		if userID != exceptUserID {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	if err := hnd.Hub.Publish(evt, userIDs...); err != nil {
+		log.Printf("error while publishing the chat event %q: %v\n", evt.Type, err)
+	}
+}
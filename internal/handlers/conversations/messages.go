@@ -0,0 +1,165 @@
+package conversations
+
+import (
+	"encoding/json"
+	"log"
+	"marketplace/internal/chat"
+	"marketplace/internal/conversations"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// minMessageLen — минимальная длина сообщения
+	minMessageLen int = 1
+	// maxMessageLen — максимальная длина сообщения
+	maxMessageLen int = 2000
+)
+
+// SendMessageRequest — запрос на отправку сообщения
+type SendMessageRequest struct {
+	// Text — текст сообщения
+	Text string `json:"text"`
+}
+
+// StartConversation отправляет сообщение продавцу объявления от имени текущего пользователя
+func (hnd *ConversationsHandler) StartConversation(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+	hnd.sendMessage(wrt, rqt, func(userID, text string) (*conversations.Message, int, error) {
+		return hnd.ConversationsRepo.StartConversation(cardID, userID, text)
+	})
+}
+
+// SendMessage отправляет сообщение в переписку от имени текущего пользователя
+func (hnd *ConversationsHandler) SendMessage(wrt http.ResponseWriter, rqt *http.Request) {
+	conversationID := mux.Vars(rqt)["id"]
+	hnd.sendMessage(wrt, rqt, func(userID, text string) (*conversations.Message, int, error) {
+		return hnd.ConversationsRepo.SendMessage(conversationID, userID, text)
+	})
+}
+
+// sendMessage валидирует запрос на отправку сообщения, отправляет сообщение и возвращает его в ответе
+func (hnd *ConversationsHandler) sendMessage(wrt http.ResponseWriter, rqt *http.Request,
+	send func(userID, text string) (*conversations.Message, int, error)) {
+	var srq SendMessageRequest
+	err := json.NewDecoder(rqt.Body).Decode(&srq)
+	if err != nil {
+		errSend := hdr.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	check := utils.CheckLen(srq.Text, "недостаточная", "превышена допустимая", "сообщения", "сообщение", minMessageLen, maxMessageLen)
+	if check != "" {
+		errSend := hdr.SendBadReq(wrt, check)
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	msg, code, err := send(userID, srq.Text)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	hnd.publish(chat.Event{Type: chat.EventMessage, ConversationID: msg.ConversationID, Username: msg.Sender, Message: msg}, "")
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(msg)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// GetConversations получает переписки текущего пользователя
+func (hnd *ConversationsHandler) GetConversations(wrt http.ResponseWriter, rqt *http.Request) {
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	cnvs, err := hnd.ConversationsRepo.GetConversations(userID)
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(cnvs)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// GetMessages получает сообщения переписки, начиная с самых новых, и отмечает
+// сообщения собеседника прочитанными
+func (hnd *ConversationsHandler) GetMessages(wrt http.ResponseWriter, rqt *http.Request) {
+	conversationID := mux.Vars(rqt)["id"]
+
+	queryParams := rqt.URL.Query()
+	page := 1
+	if pageParam := queryParams.Get("page"); pageParam != "" {
+		if pageInt, err := strconv.Atoi(pageParam); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	perPage := 50
+	if perPageParam := queryParams.Get("per_page"); perPageParam != "" {
+		if perPageInt, err := strconv.Atoi(perPageParam); err == nil && perPageInt > 0 {
+			perPage = perPageInt
+		}
+	}
+
+	username, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	msgs, code, err := hnd.ConversationsRepo.GetMessages(conversationID, userID, perPage, (page-1)*perPage)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	code, err = hnd.ConversationsRepo.MarkRead(conversationID, userID)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+	hnd.publish(chat.Event{Type: chat.EventRead, ConversationID: conversationID, Username: username}, userID)
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(msgs)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
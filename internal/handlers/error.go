@@ -10,6 +10,9 @@ import (
 const (
 	BadRequestCode          int = 400
 	UnauthorizedCode        int = 401
+	ForbiddenCode           int = 403
+	NotFoundCode            int = 404
+	ConflictCode            int = 409
 	InternalServerErrorCode int = 500
 	OKCode                  int = 200
 )
@@ -42,3 +45,39 @@ func SendUnauthorized(wrt http.ResponseWriter, errStr string) error {
 	errResp := RespondWithError(wrt, err, http.StatusUnauthorized)
 	return errResp
 }
+
+func SendConflict(wrt http.ResponseWriter, errStr string) error {
+	err := fmt.Sprintf("Конфликт: %s", errStr)
+	errResp := RespondWithError(wrt, err, http.StatusConflict)
+	return errResp
+}
+
+func SendNotFound(wrt http.ResponseWriter, errStr string) error {
+	err := fmt.Sprintf("Не найдено: %s", errStr)
+	errResp := RespondWithError(wrt, err, http.StatusNotFound)
+	return errResp
+}
+
+func SendForbidden(wrt http.ResponseWriter, errStr string) error {
+	err := fmt.Sprintf("Доступ запрещен: %s", errStr)
+	errResp := RespondWithError(wrt, err, http.StatusForbidden)
+	return errResp
+}
+
+// SendByCode отправляет сообщение об ошибке, соответствующее коду состояния ответа
+func SendByCode(wrt http.ResponseWriter, statusCode int, errStr string) error {
+	switch statusCode {
+	case BadRequestCode:
+		return SendBadReq(wrt, errStr)
+	case UnauthorizedCode:
+		return SendUnauthorized(wrt, errStr)
+	case ForbiddenCode:
+		return SendForbidden(wrt, errStr)
+	case NotFoundCode:
+		return SendNotFound(wrt, errStr)
+	case ConflictCode:
+		return SendConflict(wrt, errStr)
+	default:
+		return SendInternalServerError(wrt, errStr)
+	}
+}
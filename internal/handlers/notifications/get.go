@@ -0,0 +1,68 @@
+package notifications
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"net/http"
+	"strconv"
+)
+
+// GetNotifications получает уведомления текущего пользователя
+func (hnd *NotificationsHandler) GetNotifications(wrt http.ResponseWriter, rqt *http.Request) {
+	queryParams := rqt.URL.Query()
+	page := 1
+	if pageParam := queryParams.Get("page"); pageParam != "" {
+		if pageInt, err := strconv.Atoi(pageParam); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	perPage := 20
+	if perPageParam := queryParams.Get("per_page"); perPageParam != "" {
+		if perPageInt, err := strconv.Atoi(perPageParam); err == nil && perPageInt > 0 {
+			perPage = perPageInt
+		}
+	}
+
+	unreadOnly := queryParams.Get("unread") == "true"
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	ntfs, err := hnd.NotificationsRepo.GetNotifications(userID, unreadOnly, perPage, (page-1)*perPage)
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(ntfs)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// MarkAllRead отмечает все уведомления текущего пользователя прочитанными
+func (hnd *NotificationsHandler) MarkAllRead(wrt http.ResponseWriter, rqt *http.Request) {
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	if err := hnd.NotificationsRepo.MarkAllRead(userID); err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,9 @@
+package notifications
+
+import (
+	"marketplace/internal/notifications"
+)
+
+type NotificationsHandler struct {
+	NotificationsRepo notifications.NotificationsRepo
+}
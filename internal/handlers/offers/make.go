@@ -0,0 +1,126 @@
+package offers
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/offers"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// OfferRequest — запрос с предложенной ценой
+type OfferRequest struct {
+	// Amount — предложенная цена
+	Amount float64 `json:"amount"`
+}
+
+// MakeOffer создает предложение цены текущего пользователя по объявлению
+func (hnd *OffersHandler) MakeOffer(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	amount, ok := decodeAmount(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	offer, code, err := hnd.OffersRepo.MakeOffer(cardID, userID, amount)
+	sendOffer(wrt, offer, code, err)
+}
+
+// GetCardOffers получает предложения по объявлению текущего пользователя
+func (hnd *OffersHandler) GetCardOffers(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	offersList, code, err := hnd.OffersRepo.GetCardOffers(cardID, userID)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(offersList)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// GetUserOffers получает предложения, в которых текущий пользователь является покупателем или продавцом
+func (hnd *OffersHandler) GetUserOffers(wrt http.ResponseWriter, rqt *http.Request) {
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	offersList, err := hnd.OffersRepo.GetUserOffers(userID)
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(offersList)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// decodeAmount получает предложенную цену из тела запроса.
+// В случае ошибки отправляет сообщение об ошибке и возвращает false
+func decodeAmount(wrt http.ResponseWriter, rqt *http.Request) (float64, bool) {
+	var orq OfferRequest
+	err := json.NewDecoder(rqt.Body).Decode(&orq)
+	if err != nil {
+		errSend := hdr.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return 0, false
+	}
+
+	if orq.Amount <= 0 {
+		errSend := hdr.SendBadReq(wrt, "ошибка: предложенная цена должна быть выше 0")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return 0, false
+	}
+	return orq.Amount, true
+}
+
+// sendOffer отправляет предложение или сообщение об ошибке по коду состояния ответа
+func sendOffer(wrt http.ResponseWriter, offer *offers.Offer, code int, err error) {
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(offer)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
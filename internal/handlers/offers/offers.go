@@ -0,0 +1,9 @@
+package offers
+
+import (
+	"marketplace/internal/offers"
+)
+
+type OffersHandler struct {
+	OffersRepo offers.OffersRepo
+}
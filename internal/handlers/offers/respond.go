@@ -0,0 +1,52 @@
+package offers
+
+import (
+	hdr "marketplace/internal/handlers"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// AcceptOffer принимает предложение, объявление переходит в статус reserved
+func (hnd *OffersHandler) AcceptOffer(wrt http.ResponseWriter, rqt *http.Request) {
+	offerID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	offer, code, err := hnd.OffersRepo.AcceptOffer(offerID, userID)
+	sendOffer(wrt, offer, code, err)
+}
+
+// RejectOffer отклоняет предложение
+func (hnd *OffersHandler) RejectOffer(wrt http.ResponseWriter, rqt *http.Request) {
+	offerID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	offer, code, err := hnd.OffersRepo.RejectOffer(offerID, userID)
+	sendOffer(wrt, offer, code, err)
+}
+
+// CounterOffer отвечает на предложение встречным предложением
+func (hnd *OffersHandler) CounterOffer(wrt http.ResponseWriter, rqt *http.Request) {
+	offerID := mux.Vars(rqt)["id"]
+
+	amount, ok := decodeAmount(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	offer, code, err := hnd.OffersRepo.CounterOffer(offerID, userID, amount)
+	sendOffer(wrt, offer, code, err)
+}
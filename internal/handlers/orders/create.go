@@ -0,0 +1,129 @@
+package orders
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/orders"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateOrderRequest — запрос на создание заказа по объявлению или по принятому предложению цены
+type CreateOrderRequest struct {
+	// CardID — объявление, покупаемое по его цене
+	CardID string `json:"card_id,omitempty"`
+	// OfferID — принятое предложение цены
+	OfferID string `json:"offer_id,omitempty"`
+}
+
+// CreateOrder создает заказ текущего пользователя и платеж по нему
+func (hnd *OrdersHandler) CreateOrder(wrt http.ResponseWriter, rqt *http.Request) {
+	var crq CreateOrderRequest
+	err := json.NewDecoder(rqt.Body).Decode(&crq)
+	if err != nil || (crq.CardID == "") == (crq.OfferID == "") {
+		errSend := hdr.SendBadReq(wrt, "ошибка: должен быть задан либо card_id, либо offer_id")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	var order *orders.Order
+	var code int
+	if crq.CardID != "" {
+		order, code, err = hnd.OrdersRepo.CreateOrder(crq.CardID, userID)
+	} else {
+		order, code, err = hnd.OrdersRepo.CreateOrderFromOffer(crq.OfferID, userID)
+	}
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	payment, err := hnd.Payments.CreatePayment(order.ID, order.Amount)
+	if err == nil {
+		var orderWithPayment *orders.Order
+		orderWithPayment, err = hnd.OrdersRepo.SetPayment(order.ID, payment.ID, payment.URL)
+		if err == nil {
+			order = orderWithPayment
+		}
+	}
+	if err != nil {
+		// Заказ без платежа не может быть оплачен, поэтому он отменяется
+		if _, _, errCancel := hnd.OrdersRepo.ChangeStatus(order.ID, userID, orders.StatusCancelled); errCancel != nil {
+			log.Printf("error while cancelling the order %s without payment: %v\n", order.ID, errCancel)
+		}
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	sendOrder(wrt, order, hdr.OKCode, nil)
+}
+
+// GetOrder получает заказ текущего пользователя
+func (hnd *OrdersHandler) GetOrder(wrt http.ResponseWriter, rqt *http.Request) {
+	orderID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	order, code, err := hnd.OrdersRepo.GetOrder(orderID, userID)
+	sendOrder(wrt, order, code, err)
+}
+
+// GetUserOrders получает заказы, в которых текущий пользователь является покупателем или продавцом
+func (hnd *OrdersHandler) GetUserOrders(wrt http.ResponseWriter, rqt *http.Request) {
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	ordersList, err := hnd.OrdersRepo.GetUserOrders(userID)
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(ordersList)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// sendOrder отправляет заказ или сообщение об ошибке по коду состояния ответа
+func sendOrder(wrt http.ResponseWriter, order *orders.Order, code int, err error) {
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(order)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
@@ -0,0 +1,11 @@
+package orders
+
+import (
+	"marketplace/internal/orders"
+	"marketplace/internal/payments"
+)
+
+type OrdersHandler struct {
+	OrdersRepo orders.OrdersRepo
+	Payments   payments.PaymentProvider
+}
@@ -0,0 +1,81 @@
+package orders
+
+import (
+	"context"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/orders"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// ShipOrder отмечает оплаченный заказ отправленным продавцом
+func (hnd *OrdersHandler) ShipOrder(wrt http.ResponseWriter, rqt *http.Request) {
+	hnd.changeStatus(wrt, rqt, orders.StatusShipped)
+}
+
+// CompleteOrder подтверждает получение заказа покупателем и завершает сделку
+func (hnd *OrdersHandler) CompleteOrder(wrt http.ResponseWriter, rqt *http.Request) {
+	hnd.changeStatus(wrt, rqt, orders.StatusCompleted)
+}
+
+// CancelOrder отменяет заказ. Платеж оплаченного заказа возвращается покупателю
+func (hnd *OrdersHandler) CancelOrder(wrt http.ResponseWriter, rqt *http.Request) {
+	hnd.changeStatus(wrt, rqt, orders.StatusCancelled)
+}
+
+// changeStatus изменяет статус заказа текущего пользователя
+func (hnd *OrdersHandler) changeStatus(wrt http.ResponseWriter, rqt *http.Request, status string) {
+	orderID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	order, code, err := hnd.OrdersRepo.ChangeStatus(orderID, userID, status)
+	if code == hdr.OKCode && order.RefundStatus != nil && *order.RefundStatus == orders.RefundPending &&
+		hnd.refund(orders.Refund{OrderID: order.ID, PaymentID: *order.PaymentID}) {
+		refunded := orders.RefundRefunded
+		order.RefundStatus = &refunded
+	}
+	sendOrder(wrt, order, code, err)
+}
+
+// refund возвращает платеж отмененного заказа и отмечает его возвращенным.
+// При ошибке платеж остается ожидающим возврата и возвращается позже RunRefundSweeper
+func (hnd *OrdersHandler) refund(rfd orders.Refund) bool {
+	if err := hnd.Payments.Refund(rfd.PaymentID); err != nil {
+		log.Printf("error while refunding the payment %s of the order %s: %v\n", rfd.PaymentID, rfd.OrderID, err)
+		return false
+	}
+	if err := hnd.OrdersRepo.MarkRefunded(rfd.OrderID); err != nil {
+		log.Printf("error while marking the order %s as refunded: %v\n", rfd.OrderID, err)
+		return false
+	}
+	return true
+}
+
+// RunRefundSweeper периодически повторяет возврат платежей, ожидающих возврата, до отмены контекста
+func (hnd *OrdersHandler) RunRefundSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		refunds, err := hnd.OrdersRepo.GetPendingRefunds()
+		if err != nil {
+			log.Printf("error while getting pending refunds: %v\n", err)
+		}
+		for _, rfd := range refunds {
+			hnd.refund(rfd)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
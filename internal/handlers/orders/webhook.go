@@ -0,0 +1,67 @@
+package orders
+
+import (
+	"errors"
+	"io"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/orders"
+	"marketplace/internal/payments"
+	"net/http"
+)
+
+// maxWebhookBytes — максимальный размер уведомления платежного провайдера в байтах
+const maxWebhookBytes int64 = 64 << 10
+
+// PaymentWebhook принимает уведомление платежного провайдера о статусе платежа:
+// проведенный платеж оплачивает заказ, непроведенный — отменяет его. Платеж за отмененный заказ возвращается.
+// Уведомление принимается только с корректной подписью в заголовке X-Signature
+func (hnd *OrdersHandler) PaymentWebhook(wrt http.ResponseWriter, rqt *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(rqt.Body, maxWebhookBytes))
+	if err != nil {
+		errSend := hdr.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	evt, err := hnd.Payments.ParseWebhook(body, rqt.Header.Get(payments.SignatureHeader))
+	if errors.Is(err, payments.ErrInvalidSignature) {
+		errSend := hdr.SendUnauthorized(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the unauthorized error message: %v\n", errSend)
+		}
+		return
+	}
+	if err != nil {
+		errSend := hdr.SendBadReq(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	code := hdr.OKCode
+	var refund *orders.Refund
+	switch evt.Status {
+	case payments.StatusSucceeded:
+		refund, code, err = hnd.OrdersRepo.MarkPaid(evt.PaymentID)
+	case payments.StatusFailed:
+		code, err = hnd.OrdersRepo.MarkFailed(evt.PaymentID)
+	}
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	// Платеж за отмененный заказ возвращается покупателю, при ошибке возврат повторит RunRefundSweeper
+	if refund != nil {
+		hnd.refund(*refund)
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
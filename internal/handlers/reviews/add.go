@@ -0,0 +1,115 @@
+package reviews
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// minRating — минимальная оценка
+	minRating int = 1
+	// maxRating — максимальная оценка
+	maxRating int = 5
+	// minReviewLen — минимальная длина текста отзыва
+	minReviewLen int = 1
+	// maxReviewLen — максимальная длина текста отзыва
+	maxReviewLen int = 2000
+)
+
+// AddReviewRequest — запрос на добавление отзыва о продавце
+type AddReviewRequest struct {
+	// Rating — оценка от 1 до 5
+	Rating int `json:"rating"`
+	// Text — текст отзыва
+	Text string `json:"text"`
+}
+
+// AddReview добавляет отзыв текущего пользователя о продавце
+func (hnd *ReviewsHandler) AddReview(wrt http.ResponseWriter, rqt *http.Request) {
+	seller := mux.Vars(rqt)["username"]
+
+	var arq AddReviewRequest
+	err := json.NewDecoder(rqt.Body).Decode(&arq)
+	if err != nil {
+		errSend := hdr.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	errStr := utils.CheckLen(arq.Text, "недостаточная", "превышена допустимая", "текста отзыва", "текст отзыва", minReviewLen, maxReviewLen)
+	if arq.Rating < minRating || arq.Rating > maxRating {
+		errStr = "ошибка: оценка должна быть от 1 до 5"
+	}
+	if errStr != "" {
+		errSend := hdr.SendBadReq(wrt, errStr)
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	review, code, err := hnd.ReviewsRepo.AddReview(seller, userID, arq.Rating, arq.Text)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(review)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// GetReviews получает отзывы о продавце и его среднюю оценку
+func (hnd *ReviewsHandler) GetReviews(wrt http.ResponseWriter, rqt *http.Request) {
+	seller := mux.Vars(rqt)["username"]
+
+	queryParams := rqt.URL.Query()
+	page := 1
+	if pageParam := queryParams.Get("page"); pageParam != "" {
+		if pageInt, err := strconv.Atoi(pageParam); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	perPage := 20
+	if perPageParam := queryParams.Get("per_page"); perPageParam != "" {
+		if perPageInt, err := strconv.Atoi(perPageParam); err == nil && perPageInt > 0 {
+			perPage = perPageInt
+		}
+	}
+
+	result, code, err := hnd.ReviewsRepo.GetReviews(seller, perPage, (page-1)*perPage)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(result)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
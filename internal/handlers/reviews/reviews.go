@@ -0,0 +1,9 @@
+package reviews
+
+import (
+	"marketplace/internal/reviews"
+)
+
+type ReviewsHandler struct {
+	ReviewsRepo reviews.ReviewsRepo
+}
@@ -0,0 +1,156 @@
+package searches
+
+import (
+	"encoding/json"
+	"log"
+	"marketplace/internal/cards"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/searches"
+	"marketplace/internal/tags"
+	"marketplace/internal/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// minNameLen — минимальная длина названия сохраненного поиска
+	minNameLen int = 1
+	// maxNameLen — максимальная длина названия сохраненного поиска
+	maxNameLen int = 100
+)
+
+// SaveSearchRequest — запрос на сохранение поиска
+type SaveSearchRequest struct {
+	// Name — название сохраненного поиска
+	Name string `json:"name"`
+	// Filter — фильтры ленты объявлений: price_min, price_max, q, category, tags, any_tags, author
+	Filter cards.QueryParams `json:"filter"`
+}
+
+// SaveSearch сохраняет поиск текущего пользователя
+func (hnd *SearchesHandler) SaveSearch(wrt http.ResponseWriter, rqt *http.Request) {
+	var srq SaveSearchRequest
+	err := json.NewDecoder(rqt.Body).Decode(&srq)
+	if err != nil {
+		errSend := hdr.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	if !hnd.validateSearch(wrt, &srq) {
+		return
+	}
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	srch, err := hnd.SearchesRepo.SaveSearch(userID, &searches.SavedSearch{Name: srq.Name, Filter: srq.Filter})
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(srch)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// GetSearches получает сохраненные поиски текущего пользователя
+func (hnd *SearchesHandler) GetSearches(wrt http.ResponseWriter, rqt *http.Request) {
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	srchs, err := hnd.SearchesRepo.GetSearches(userID)
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(srchs)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// DeleteSearch удаляет сохраненный поиск текущего пользователя
+func (hnd *SearchesHandler) DeleteSearch(wrt http.ResponseWriter, rqt *http.Request) {
+	searchID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hdr.GetCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	code, err := hnd.SearchesRepo.DeleteSearch(userID, searchID)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
+
+// validateSearch валидирует сохраняемый поиск и нормализует его теги
+func (hnd *SearchesHandler) validateSearch(wrt http.ResponseWriter, srq *SaveSearchRequest) bool {
+	errStr := utils.CheckLen(srq.Name, "недостаточная", "превышена допустимая", "названия поиска", "название поиска", minNameLen, maxNameLen)
+	flt := &srq.Filter
+	flt.Tags = tags.Normalize(flt.Tags)
+	flt.AnyTags = tags.Normalize(flt.AnyTags)
+	if flt.Search != nil && strings.TrimSpace(*flt.Search) == "" {
+		flt.Search = nil
+	}
+
+	switch {
+	case errStr != "":
+	case flt.PriceMin == nil && flt.PriceMax == nil && flt.Search == nil && flt.CategoryID == nil &&
+		flt.Author == nil && len(flt.Tags) == 0 && len(flt.AnyTags) == 0:
+		errStr = "ошибка: в сохраненном поиске должен быть задан хотя бы один фильтр"
+	case flt.PriceMin != nil && flt.PriceMax != nil && *flt.PriceMin > *flt.PriceMax:
+		errStr = "ошибка: price_min не может быть больше price_max"
+	}
+
+	if errStr == "" && flt.CategoryID != nil {
+		exists, err := hnd.CategoriesRepo.Exists(*flt.CategoryID)
+		if err != nil {
+			errSend := hdr.SendInternalServerError(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the internal server error message: %v\n", errSend)
+			}
+			return false
+		}
+		if !exists {
+			errStr = "ошибка: категория не существует"
+		}
+	}
+
+	if errStr != "" {
+		errSend := hdr.SendBadReq(wrt, errStr)
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,11 @@
+package searches
+
+import (
+	"marketplace/internal/categories"
+	"marketplace/internal/searches"
+)
+
+type SearchesHandler struct {
+	SearchesRepo   searches.SearchesRepo
+	CategoriesRepo categories.CategoriesRepo
+}
@@ -0,0 +1,48 @@
+package tags
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultPopularLimit — количество популярных тегов по умолчанию
+	defaultPopularLimit int = 20
+	// maxPopularLimit — максимальное количество популярных тегов
+	maxPopularLimit int = 100
+)
+
+// GetPopular получает самые популярные теги
+func (hnd *TagsHandler) GetPopular(wrt http.ResponseWriter, rqt *http.Request) {
+	limit := defaultPopularLimit
+	if limitParam := rqt.URL.Query().Get("limit"); limitParam != "" {
+		limitInt, err := strconv.Atoi(limitParam)
+		if err != nil || limitInt <= 0 || limitInt > maxPopularLimit {
+			errSend := hdr.SendBadReq(wrt, "ошибка: limit должен быть целым числом от 1 до 100")
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return
+		}
+		limit = limitInt
+	}
+
+	popular, err := hnd.TagsRepo.GetPopular(limit)
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(popular)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
@@ -0,0 +1,9 @@
+package tags
+
+import (
+	"marketplace/internal/tags"
+)
+
+type TagsHandler struct {
+	TagsRepo tags.TagsRepo
+}
@@ -28,6 +28,12 @@ type AuthRequest struct {
 	Password string `json:"password"`
 }
 
+// SignUpResponse — ответ на регистрацию нового пользователя
+type SignUpResponse struct {
+	// Username — логин зарегистрированного пользователя
+	Username string `json:"username"`
+}
+
 // SignIn авторизует уже зарегистрированного пользователя
 func (hnd *UserHandler) SignIn(wrt http.ResponseWriter, rqt *http.Request) {
 	usr, _ := ProcessRequest(wrt, rqt)
@@ -56,7 +62,7 @@ func (hnd *UserHandler) SignIn(wrt http.ResponseWriter, rqt *http.Request) {
 		return
 	}
 
-	ProcessToken(wrt, rqt, user)
+	hnd.ProcessToken(wrt, rqt, user)
 }
 
 // SignUp регистрирует нового пользователя
@@ -98,8 +104,10 @@ func (hnd *UserHandler) SignUp(wrt http.ResponseWriter, rqt *http.Request) {
 		return
 	}
 
-	ProcessToken(wrt, rqt, user)
-	errJSON := json.NewEncoder(wrt).Encode(user)
+	if !hnd.ProcessToken(wrt, rqt, user) {
+		return
+	}
+	errJSON := json.NewEncoder(wrt).Encode(SignUpResponse{Username: user.Username})
 	if errJSON != nil {
 		log.Printf("error while sending response body: %v\n", errJSON)
 	}
@@ -147,20 +155,42 @@ func ProcessRequest(wrt http.ResponseWriter, rqt *http.Request) (*user.User, *Au
 	return usr, &arq
 }
 
-func ProcessToken(wrt http.ResponseWriter, rqt *http.Request, thisUser *user.User) {
-	tokenString, errToken := token.CreateJWTtoken(thisUser.Username)
-	if errToken != nil {
-		errSend := hdr.SendInternalServerError(wrt, errToken.Error())
+// ProcessToken создает сессию пользователя и передает в заголовках ответа access-токен
+// и refresh-токен. В случае ошибки отправляет сообщение об ошибке и возвращает false
+func (hnd *UserHandler) ProcessToken(wrt http.ResponseWriter, rqt *http.Request, thisUser *user.User) bool {
+	userID, err := hnd.UserRepo.GetUserID(thisUser.Username)
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
 		if errSend != nil {
 			log.Printf("error while sending the internal server error message: %v\n", errSend)
 		}
+		return false
+	}
 
+	sessionID, refreshToken, code, err := hnd.SessionsRepo.CreateSession(userID, rqt.UserAgent(), clientIP(rqt))
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return false
+	}
+
+	tokenString, errToken := token.CreateJWTtoken(userID, thisUser.Username, sessionID)
+	if errToken != nil {
 		log.Println("error while creating the JWT token: ", errToken)
+		errSend := hdr.SendInternalServerError(wrt, errToken.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return false
 	}
 
 	wrt.Header().Set("Authorization", tokenString)
+	wrt.Header().Set(RefreshTokenHeader, refreshToken)
 	wrt.Header().Set("Content-Type", "application/json")
 	wrt.WriteHeader(http.StatusOK)
+	return true
 }
 
 // validateUsername валидирует логин
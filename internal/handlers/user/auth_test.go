@@ -126,26 +126,16 @@ func TestSignUp(t *testing.T) {
 			t.Errorf("Заголовок Content-Type должен иметь MIME-тип application/json, но имеет %s", mime)
 		}
 
-		var usr user.User
-		if err := json.NewDecoder(resp.Body).Decode(&usr); err != nil {
+		var body map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
 			t.Fatalf("failed to decode response: %v", err)
 		}
 
-		hashedPassword, err := user.HashPassword(auth.Password)
-		if err != nil {
-			t.Fatalf("error while hashing the password: %v", err)
-		}
-
-		if usr.Username != auth.Username && usr.Password != hashedPassword {
-			t.Error("Логин, полученный от сервера, не совпадает с логином, отправленным пользователем. \n Также хэшированный пароль, полученный от сервера, не совпадает с отправленным пользователем паролем, который был захэширован.")
-		}
-
-		if usr.Username != auth.Username {
+		if body["username"] != auth.Username {
 			t.Error("Логин, полученный от сервера, не совпадает с логином, отправленным пользователем.")
 		}
-
-		if usr.Password != hashedPassword {
-			t.Error("Хэшированный пароль, полученный от сервера, не совпадает с отправленным пользователем паролем, который был захэширован.")
+		if _, ok := body["password"]; ok || len(body) != 1 {
+			t.Errorf("Ответ на регистрацию должен содержать только логин, но получен: %v", body)
 		}
 	})
 }
@@ -181,5 +171,13 @@ func TestSignIn(t *testing.T) {
 		if resp.StatusCode != http.StatusOK {
 			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
 		}
+
+		passwordHash, err := user.GetPasswordHash(ConnectToDB(t), auth.Username)
+		if err != nil {
+			t.Fatalf("error while selecting the password hash: %v", err)
+		}
+		if user.NeedsRehash(passwordHash) {
+			t.Errorf("Ожидалась замена устаревшего хэша пароля на argon2id после входа, но получен: %s", passwordHash)
+		}
 	})
 }
@@ -0,0 +1,57 @@
+package user
+
+import (
+	"encoding/json"
+	"log"
+	"marketplace/internal/cards"
+	"marketplace/internal/handlers"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// ChangeStatusRequest — запрос на изменение статуса объявления
+type ChangeStatusRequest struct {
+	// Status — новый статус объявления
+	Status string `json:"status"`
+}
+
+// ChangeStatus изменяет статус объявления текущего пользователя
+func (hnd *UserHandler) ChangeStatus(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	var srq ChangeStatusRequest
+	err := json.NewDecoder(rqt.Body).Decode(&srq)
+	if err != nil {
+		errSend := handlers.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	username, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	card, code, err := hnd.CardsRepo.ChangeStatus(cardID, userID, srq.Status, username)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	if card.Status == cards.StatusPublished {
+		hnd.notifySavedSearches(card.ID, userID)
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(card)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
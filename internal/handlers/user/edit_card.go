@@ -0,0 +1,112 @@
+package user
+
+import (
+	"encoding/json"
+	"log"
+	"marketplace/internal/cards"
+	"marketplace/internal/handlers"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// EditCardRequest — запрос на изменение объявления. Поля, которые не переданы, не изменяются
+type EditCardRequest struct {
+	// Title — заголовок
+	Title *string `json:"title"`
+	// Text — текст объявления
+	Text *string `json:"text"`
+	// ImageURL — ссылка на изображение
+	ImageURL *string `json:"image_url"`
+	// Price — цена
+	Price *string `json:"price"`
+	// CategoryID — категория
+	CategoryID *int `json:"category_id"`
+	// Tags — теги, заменяющие текущие теги объявления; пустой список удаляет все теги
+	Tags *[]string `json:"tags"`
+}
+
+// EditCard изменяет объявление текущего пользователя
+func (hnd *UserHandler) EditCard(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	var erq EditCardRequest
+	err := json.NewDecoder(rqt.Body).Decode(&erq)
+	if err != nil {
+		errSend := handlers.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	username, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	// Автор проверяется до валидации, чтобы не загружать изображение по запросу другого пользователя
+	code, err := hnd.CardsRepo.CheckAuthor(cardID, userID)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	if !hnd.validateCard(wrt, &erq) {
+		return
+	}
+
+	upd := &cards.CardUpdate{Title: erq.Title, Text: erq.Text, ImageURL: erq.ImageURL, CategoryID: erq.CategoryID, Tags: erq.Tags}
+	if erq.Price != nil {
+		priceFloat64, err := strconv.ParseFloat(*erq.Price, 64)
+		if err != nil {
+			errSend := handlers.SendInternalServerError(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the internal server error message: %v\n", errSend)
+			}
+			return
+		}
+		upd.Price = &priceFloat64
+	}
+
+	card, code, err := hnd.CardsRepo.EditCard(cardID, userID, upd, username)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(card)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// DeleteCard удаляет объявление текущего пользователя
+func (hnd *UserHandler) DeleteCard(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	code, err := hnd.CardsRepo.DeleteCard(cardID, userID)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,150 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForEditCard(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/sign-up", uhr.SignUp).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.GetCard, ssn, false)).Methods("GET")
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.EditCard, ssn, true)).Methods("PATCH")
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.DeleteCard, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// TestEditAndDeleteCard тестирует сценарий изменения и удаления объявления
+func TestEditAndDeleteCard(t *testing.T) {
+	ts, uhr := setupTestServerForEditCard(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	token := Authorize(t, ts, auth, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{{Title: "title12", Text: "text12", ImageURL: imageURL, Price: "12000", CategoryID: 2}}, token)
+
+	feed := GetFeed(t, ts, "/get-cards", token)
+	if len(feed) == 0 || feed[0].Title != "title12" {
+		t.Fatalf("в ленте объявлений не найдено только что созданное объявление")
+	}
+	cardURL := ts.URL + "/cards/" + feed[0].ID
+
+	otherToken := Authorize(t, ts, uhd.AuthRequest{Username: "user4", Password: "P@s5_w0rd!Ab"}, "/sign-up")
+
+	title, price := "title13", "13000"
+	t.Run("изменение чужого объявления", func(t *testing.T) {
+		// Автор проверяется раньше изображения, поэтому некорректная ссылка не приводит к ответу 400
+		invalidURL := "http://127.0.0.1:1/image.jpeg"
+		resp := DoJSON(t, http.MethodPatch, cardURL, otherToken, uhd.EditCardRequest{Title: &title, ImageURL: &invalidURL})
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusForbidden, resp.StatusCode)
+		}
+	})
+
+	t.Run("некорректная цена", func(t *testing.T) {
+		negative := "-1"
+		resp := DoJSON(t, http.MethodPatch, cardURL, token, uhd.EditCardRequest{Price: &negative})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "цена должна быть выше 0")
+	})
+
+	t.Run("изменение своего объявления", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPatch, cardURL, token, uhd.EditCardRequest{Title: &title, Price: &price, Tags: &[]string{"Phone"}})
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+		}
+
+		var card cards.CardOutput
+		if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+			t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+		}
+
+		if card.Title != "title13" || card.Text != "text12" || card.Price != 13000 || len(card.Tags) != 1 || card.Tags[0] != "phone" {
+			t.Errorf("Получено неожиданное объявление: %+v", card)
+		}
+	})
+
+	t.Run("пустой заголовок", func(t *testing.T) {
+		empty := ""
+		resp := DoJSON(t, http.MethodPatch, cardURL, token, uhd.EditCardRequest{Title: &empty})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: недостаточная длина заголовка -> заголовок должен содержать от 2 до 100 символов")
+	})
+
+	t.Run("удаление тегов", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPatch, cardURL, token, uhd.EditCardRequest{Tags: &[]string{}})
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+		}
+
+		var card cards.CardOutput
+		if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+			t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+		}
+		if len(card.Tags) != 0 || card.Title != "title13" {
+			t.Errorf("Получено неожиданное объявление: %+v", card)
+		}
+	})
+
+	t.Run("удаление чужого объявления", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodDelete, cardURL, otherToken, nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusForbidden, resp.StatusCode)
+		}
+	})
+
+	t.Run("удаление своего объявления", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodDelete, cardURL, token, nil)
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+		}
+
+		resp, err := http.Get(cardURL)
+		if err != nil {
+			t.Fatalf("failed to issue a GET request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNotFound, resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package user
+
+import (
+	"log"
+	"marketplace/internal/handlers"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// AddFavorite добавляет объявление в избранное текущего пользователя
+func (hnd *UserHandler) AddFavorite(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	code, err := hnd.CardsRepo.AddFavorite(cardID, userID)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveFavorite удаляет объявление из избранного текущего пользователя
+func (hnd *UserHandler) RemoveFavorite(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	code, err := hnd.CardsRepo.RemoveFavorite(cardID, userID)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
+
+// GetFavorites получает избранные объявления текущего пользователя
+func (hnd *UserHandler) GetFavorites(wrt http.ResponseWriter, rqt *http.Request) {
+	params, ok := parseFeedParams(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	username, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+	params.Username = &username
+	params.FavoritesOf = &userID
+
+	respondWithFeed(wrt, rqt, params, hnd.CardsRepo.GetCards, hnd.CardsRepo.CountCards)
+}
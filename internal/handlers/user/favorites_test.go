@@ -0,0 +1,107 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForFavorites(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/sign-up", uhr.SignUp).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.GetCard, ssn, false)).Methods("GET")
+	rtr.HandleFunc(cardPath+"/favorite", middleware.RequireAuth(uhr.AddFavorite, ssn, true)).Methods("POST")
+	rtr.HandleFunc(cardPath+"/favorite", middleware.RequireAuth(uhr.RemoveFavorite, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc("/me/favorites", middleware.RequireAuth(uhr.GetFavorites, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// TestFavorites тестирует сценарий добавления объявления в избранное и удаления из него
+func TestFavorites(t *testing.T) {
+	ts, uhr := setupTestServerForFavorites(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{{Title: "title14", Text: "text14", ImageURL: imageURL, Price: "14000", CategoryID: 2}}, sellerToken)
+
+	feed := GetFeed(t, ts, "/get-cards", sellerToken)
+	if len(feed) == 0 || feed[0].Title != "title14" {
+		t.Fatalf("в ленте объявлений не найдено только что созданное объявление")
+	}
+	cardURL := ts.URL + "/cards/" + feed[0].ID
+
+	buyerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user5", Password: "B^y3r_Pa55word"}, "/sign-up")
+
+	resp := DoJSON(t, http.MethodPost, cardURL+"/favorite", buyerToken, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	favorites := GetFeed(t, ts, "/me/favorites", buyerToken)
+	if len(favorites) != 1 || favorites[0].ID != feed[0].ID {
+		t.Fatalf("Ожидалось одно избранное объявление, но получено %d", len(favorites))
+	}
+	if favorites[0].IsFavorite == nil || !*favorites[0].IsFavorite {
+		t.Error("Ожидался признак is_favorite = true")
+	}
+	if favorites[0].FavoritesCount == nil || *favorites[0].FavoritesCount != 1 {
+		t.Error("Ожидалось favorites_count = 1")
+	}
+
+	resp, err := http.Get(cardURL)
+	if err != nil {
+		t.Fatalf("failed to issue a GET request: %v", err)
+	}
+	var card cards.CardOutput
+	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	resp.Body.Close()
+	if card.IsFavorite != nil || card.FavoritesCount != nil {
+		t.Error("Для неавторизованного пользователя не ожидались поля is_favorite и favorites_count")
+	}
+
+	resp = DoJSON(t, http.MethodDelete, cardURL+"/favorite", buyerToken, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	favorites = GetFeed(t, ts, "/me/favorites", buyerToken)
+	if len(favorites) != 0 {
+		t.Errorf("Ожидался пустой список избранного, но получено %d объявлений", len(favorites))
+	}
+
+	resp = DoJSON(t, http.MethodPost, ts.URL+"/cards/00000000-0000-0000-0000-000000000000/favorite", buyerToken, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
@@ -0,0 +1,106 @@
+package user
+
+import (
+	"encoding/json"
+	"log"
+	"marketplace/internal/handlers"
+	"marketplace/internal/user"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Follow подписывает текущего пользователя на продавца
+func (hnd *UserHandler) Follow(wrt http.ResponseWriter, rqt *http.Request) {
+	hnd.changeFollow(wrt, rqt, hnd.UserRepo.Follow)
+}
+
+// Unfollow отменяет подписку текущего пользователя на продавца
+func (hnd *UserHandler) Unfollow(wrt http.ResponseWriter, rqt *http.Request) {
+	hnd.changeFollow(wrt, rqt, hnd.UserRepo.Unfollow)
+}
+
+// changeFollow изменяет подписку текущего пользователя на продавца из пути запроса
+func (hnd *UserHandler) changeFollow(wrt http.ResponseWriter, rqt *http.Request, change func(followerID, seller string) (int, error)) {
+	seller := mux.Vars(rqt)["username"]
+
+	_, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	code, err := change(userID, seller)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
+
+// GetFollowers получает подписчиков пользователя
+func (hnd *UserHandler) GetFollowers(wrt http.ResponseWriter, rqt *http.Request) {
+	sendFollows(wrt, rqt, hnd.UserRepo.GetFollowers)
+}
+
+// GetFollowing получает продавцов, на которых подписан пользователь
+func (hnd *UserHandler) GetFollowing(wrt http.ResponseWriter, rqt *http.Request) {
+	sendFollows(wrt, rqt, hnd.UserRepo.GetFollowing)
+}
+
+// sendFollows отправляет страницу списка подписчиков или подписок пользователя из пути запроса
+func sendFollows(wrt http.ResponseWriter, rqt *http.Request, getFollows func(username string, limit, offset int) ([]user.Follow, int, error)) {
+	username := mux.Vars(rqt)["username"]
+
+	queryParams := rqt.URL.Query()
+	page := 1
+	if pageParam := queryParams.Get("page"); pageParam != "" {
+		if pageInt, err := strconv.Atoi(pageParam); err == nil && pageInt > 0 {
+			page = pageInt
+		}
+	}
+
+	perPage := 20
+	if perPageParam := queryParams.Get("per_page"); perPageParam != "" {
+		if perPageInt, err := strconv.Atoi(perPageParam); err == nil && perPageInt > 0 {
+			perPage = perPageInt
+		}
+	}
+
+	follows, code, err := getFollows(username, perPage, (page-1)*perPage)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(follows)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// GetFollowingFeed получает ленту объявлений продавцов, на которых подписан текущий пользователь
+func (hnd *UserHandler) GetFollowingFeed(wrt http.ResponseWriter, rqt *http.Request) {
+	params, ok := parseFeedParams(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	username, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+	params.Username = &username
+	params.FollowedBy = &userID
+
+	respondWithFeed(wrt, rqt, params, hnd.CardsRepo.GetCards, hnd.CardsRepo.CountCards)
+}
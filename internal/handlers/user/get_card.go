@@ -0,0 +1,39 @@
+package user
+
+import (
+	"encoding/json"
+	"log"
+	"marketplace/internal/handlers"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// GetCard получает объявление по его идентификатору
+func (hnd *UserHandler) GetCard(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	card, code, err := hnd.CardsRepo.GetCard(cardID, optionalUsername(rqt))
+	switch code {
+	case handlers.NotFoundCode:
+		errSend := handlers.SendNotFound(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the not found error message: %v\n", errSend)
+		}
+		return
+
+	case handlers.InternalServerErrorCode:
+		errSend := handlers.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(card)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
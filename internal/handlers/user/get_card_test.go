@@ -0,0 +1,96 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForGetCard(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.GetCard, ssn, false)).Methods("GET")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// TestGetCard тестирует сценарий получения объявления по его идентификатору
+func TestGetCard(t *testing.T) {
+	ts, uhr := setupTestServerForGetCard(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	token := Authorize(t, ts, auth, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{{Title: "title11", Text: "text11", ImageURL: imageURL, Price: "11000", CategoryID: 2}}, token)
+
+	feed := GetFeed(t, ts, "/get-cards", token)
+	if len(feed) == 0 || feed[0].Title != "title11" {
+		t.Fatalf("в ленте объявлений не найдено только что созданное объявление")
+	}
+
+	t.Run("существующее объявление", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, ts.URL+"/cards/"+feed[0].ID, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		req.Header.Set("Authorization", token)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make a request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+		}
+
+		var card cards.CardOutput
+		if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+			t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+		}
+
+		if card.ID != feed[0].ID || card.Title != "title11" || card.Username != auth.Username || !card.IsOwned {
+			t.Errorf("Получено неожиданное объявление: %+v", card)
+		}
+
+		if card.CreatedAt.IsZero() {
+			t.Error("Ожидалась дата создания объявления")
+		}
+	})
+
+	t.Run("несуществующее объявление", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/cards/00000000-0000-0000-0000-000000000000")
+		if err != nil {
+			t.Fatalf("failed to issue a GET request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNotFound, resp.StatusCode)
+		}
+	})
+}
@@ -2,18 +2,164 @@ package user
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"marketplace/internal/cards"
 	"marketplace/internal/handlers"
-	"marketplace/internal/middleware"
-	"marketplace/internal/token"
+	"marketplace/internal/tags"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
+// CardsPageResponse — страница ленты объявлений при постраничном выводе по курсору
+type CardsPageResponse struct {
+	// Items — объявления
+	Items []cards.CardOutput `json:"items"`
+	// NextCursor — курсор следующей страницы, null на последней странице
+	NextCursor *string `json:"next_cursor"`
+}
+
+// CardsEnvelopeResponse — страница ленты объявлений с общим количеством объявлений (envelope=true)
+type CardsEnvelopeResponse struct {
+	// Items — объявления
+	Items []cards.CardOutput `json:"items"`
+	// Total — общее количество объявлений, удовлетворяющих фильтрам
+	Total int `json:"total"`
+	// Page — номер страницы
+	Page int `json:"page"`
+	// PerPage — количество объявлений на странице
+	PerPage int `json:"per_page"`
+	// HasNext — признак наличия следующей страницы
+	HasNext bool `json:"has_next"`
+}
+
 // GetCards получает ленту объявлений
 func (hnd *UserHandler) GetCards(wrt http.ResponseWriter, rqt *http.Request) {
+	params, ok := parseFeedParams(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	username := optionalUsername(rqt)
+	params.Username = username
+
+	mine, ok := parseMineParam(wrt, rqt.URL.Query().Get("mine"))
+	if !ok {
+		return
+	}
+	if mine {
+		if username == nil {
+			errSend := handlers.SendUnauthorized(wrt, "для параметра mine требуется авторизация")
+			if errSend != nil {
+				log.Printf("error while sending the unauthorized error message: %v\n", errSend)
+			}
+			return
+		}
+		params.Author = username
+	}
+
+	respondWithFeed(wrt, rqt, params, hnd.CardsRepo.GetCards, hnd.CardsRepo.CountCards)
+}
+
+// optionalUsername получает логин пользователя, если запрос выполнен с токеном, иначе nil
+func optionalUsername(rqt *http.Request) *string {
+	user, ok := handlers.UserFromContext(rqt.Context())
+	if !ok {
+		return nil
+	}
+	return &user.Username
+}
+
+// respondWithFeed получает страницу ленты объявлений и отправляет ее в одном из форматов:
+// массив объявлений (по умолчанию), страница по курсору (параметр cursor) или
+// страница с общим количеством объявлений (envelope=true). Ссылки на соседние страницы
+// передаются в заголовке Link (RFC 8288)
+func respondWithFeed(wrt http.ResponseWriter, rqt *http.Request, params *cards.QueryParams,
+	getCards func(*cards.QueryParams) ([]cards.CardOutput, error), countCards func(*cards.QueryParams) (int, error)) {
+	queryParams := rqt.URL.Query()
+	isCursorMode := queryParams.Has("cursor")
+	isEnvelope := queryParams.Get("envelope") == "true"
+
+	// Запрашивается на одно объявление больше, чтобы узнать, есть ли следующая страница
+	perPage := params.PerPage
+	params.PerPage++
+	cardsList, err := getCards(params)
+	params.PerPage = perPage
+	if err != nil {
+		errSend := handlers.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	hasNext := len(cardsList) > perPage
+	if hasNext {
+		cardsList = cardsList[:perPage]
+	}
+	items := append([]cards.CardOutput{}, cardsList...)
+	page := params.Offset/perPage + 1
+
+	var links []string
+	var resp any = cardsList
+	switch {
+	case isCursorMode:
+		cursorResp := CardsPageResponse{Items: items}
+		if hasNext {
+			nextCursor := cards.NewCursor(&items[len(items)-1], params.SortBy).Encode()
+			cursorResp.NextCursor = &nextCursor
+			links = append(links, buildLink(rqt, "next", map[string]string{"cursor": nextCursor}))
+		}
+		resp = cursorResp
+
+	default:
+		if hasNext {
+			links = append(links, buildLink(rqt, "next", map[string]string{"page": strconv.Itoa(page + 1)}))
+		}
+		if page > 1 {
+			links = append(links, buildLink(rqt, "prev", map[string]string{"page": strconv.Itoa(page - 1)}))
+		}
+
+		if isEnvelope {
+			total, err := countCards(params)
+			if err != nil {
+				errSend := handlers.SendInternalServerError(wrt, err.Error())
+				if errSend != nil {
+					log.Printf("error while sending the internal server error message: %v\n", errSend)
+				}
+				return
+			}
+			resp = CardsEnvelopeResponse{Items: items, Total: total, Page: page, PerPage: perPage, HasNext: hasNext}
+		}
+	}
+
+	if len(links) > 0 {
+		wrt.Header().Set("Link", strings.Join(links, ", "))
+	}
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(resp)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// buildLink создает значение заголовка Link для текущего запроса с замененными параметрами
+func buildLink(rqt *http.Request, rel string, replace map[string]string) string {
+	linkURL := *rqt.URL
+	query := linkURL.Query()
+	for key, value := range replace {
+		query.Set(key, value)
+	}
+	linkURL.RawQuery = query.Encode()
+	return fmt.Sprintf("<%s>; rel=\"%s\"", linkURL.RequestURI(), rel)
+}
+
+// parseFeedParams разбирает параметры запроса ленты объявлений.
+// В случае ошибки отправляет сообщение об ошибке и возвращает false
+func parseFeedParams(wrt http.ResponseWriter, rqt *http.Request) (*cards.QueryParams, bool) {
 	queryParams := rqt.URL.Query()
 	page := 1
 	if pageParam := queryParams.Get("page"); pageParam != "" {
@@ -30,9 +176,35 @@ func (hnd *UserHandler) GetCards(wrt http.ResponseWriter, rqt *http.Request) {
 	}
 
 	offset := (page - 1) * perPage
+
+	var categoryID *int
+	if categoryParam := queryParams.Get("category"); categoryParam != "" {
+		categoryInt, err := strconv.Atoi(categoryParam)
+		if err != nil || categoryInt <= 0 {
+			errSend := handlers.SendBadReq(wrt, "ошибка: некорректный идентификатор категории")
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return nil, false
+		}
+		categoryID = &categoryInt
+	}
+
+	tags := parseTags(queryParams.Get("tags"))
+	anyTags := parseTags(queryParams.Get("any_tags"))
+
+	var search *string
+	if searchParam := strings.TrimSpace(queryParams.Get("q")); searchParam != "" {
+		search = &searchParam
+	}
+
 	sortBy := strings.ToLower(queryParams.Get("sort_by"))
 	switch sortBy {
 	case "price":
+	case cards.SortByRelevance:
+		if search == nil {
+			sortBy = "created_at"
+		}
 	default:
 		sortBy = "created_at"
 	}
@@ -42,58 +214,145 @@ func (hnd *UserHandler) GetCards(wrt http.ResponseWriter, rqt *http.Request) {
 		order = "desc"
 	}
 
+	// Пустой параметр cursor запрашивает первую страницу, поэтому проверяется наличие параметра, а не его значение
+	if queryParams.Has("cursor") && sortBy == cards.SortByRelevance {
+		errSend := handlers.SendBadReq(wrt, "ошибка: курсор не поддерживается при сортировке по релевантности")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return nil, false
+	}
+
+	var after *cards.Cursor
+	if cursorParam := queryParams.Get("cursor"); cursorParam != "" {
+		cursor, err := cards.DecodeCursor(cursorParam, sortBy)
+		if err != nil {
+			errSend := handlers.SendBadReq(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return nil, false
+		}
+		after = cursor
+	}
+
 	var priceMin, priceMax *float64
 
 	if priceMinParam := queryParams.Get("price_min"); priceMinParam != "" {
-		if priceMinFloat, err := strconv.ParseFloat(priceMinParam, 64); err == nil {
-			priceMin = &priceMinFloat
+		priceMinFloat, err := strconv.ParseFloat(priceMinParam, 64)
+		if err != nil {
+			errSend := handlers.SendBadReq(wrt, "ошибка: price_min должен быть числом")
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return nil, false
 		}
+		priceMin = &priceMinFloat
 	}
 	if priceMaxParam := queryParams.Get("price_max"); priceMaxParam != "" {
-		if priceMaxFloat, err := strconv.ParseFloat(priceMaxParam, 64); err == nil {
-			priceMax = &priceMaxFloat
+		priceMaxFloat, err := strconv.ParseFloat(priceMaxParam, 64)
+		if err != nil {
+			errSend := handlers.SendBadReq(wrt, "ошибка: price_max должен быть числом")
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return nil, false
 		}
+		priceMax = &priceMaxFloat
+	}
+
+	var author *string
+	if authorParam := queryParams.Get("author"); authorParam != "" {
+		author = &authorParam
 	}
 
-	authVal := rqt.Context().Value(middleware.KeyIsAuthenticated)
-	isAuthenticated, _ := authVal.(bool)
+	createdAfter, ok := parseDateParam(wrt, queryParams.Get("created_after"), "created_after")
+	if !ok {
+		return nil, false
+	}
+	createdBefore, ok := parseDateParam(wrt, queryParams.Get("created_before"), "created_before")
+	if !ok {
+		return nil, false
+	}
+	if createdAfter != nil && createdBefore != nil && !createdAfter.Before(*createdBefore) {
+		errSend := handlers.SendBadReq(wrt, "ошибка: created_after должен быть раньше created_before")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return nil, false
+	}
 
-	var username *string = nil
-	if isAuthenticated {
-		usernameStr, err := token.GetPayload(rqt)
-		if err != nil {
-			errSend := handlers.SendUnauthorized(wrt, err.Error())
+	var status *string
+	if statusParam := strings.ToLower(queryParams.Get("status")); statusParam != "" {
+		if !cards.IsValidStatus(statusParam) {
+			errSend := handlers.SendBadReq(wrt, "ошибка: неизвестный статус объявления")
 			if errSend != nil {
-				log.Printf("error while sending the unauthorized error message: %v\n", errSend)
+				log.Printf("error while sending the bad request message: %v\n", errSend)
 			}
-			return
+			return nil, false
 		}
-		username = &usernameStr
+		status = &statusParam
 	}
 
 	params := &cards.QueryParams{
-		PerPage:  perPage,
-		Offset:   offset,
-		SortBy:   sortBy,
-		Order:    order,
-		PriceMin: priceMin,
-		PriceMax: priceMax,
-		Username: username,
+		PerPage:       perPage,
+		Offset:        offset,
+		SortBy:        sortBy,
+		Order:         order,
+		PriceMin:      priceMin,
+		PriceMax:      priceMax,
+		Author:        author,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		Status:        status,
+		Search:        search,
+		CategoryID:    categoryID,
+		Tags:          tags,
+		AnyTags:       anyTags,
+		After:         after,
 	}
+	return params, true
+}
 
-	cards, err := hnd.CardsRepo.GetCards(params)
+// parseMineParam разбирает логический параметр mine.
+// В случае ошибки отправляет сообщение об ошибке и возвращает false
+func parseMineParam(wrt http.ResponseWriter, mineParam string) (bool, bool) {
+	if mineParam == "" {
+		return false, true
+	}
+
+	mine, err := strconv.ParseBool(mineParam)
 	if err != nil {
-		errSend := handlers.SendInternalServerError(wrt, err.Error())
+		errSend := handlers.SendBadReq(wrt, "ошибка: mine должен быть логическим значением")
 		if errSend != nil {
-			log.Printf("error while sending the internal server error message: %v\n", errSend)
+			log.Printf("error while sending the bad request message: %v\n", errSend)
 		}
-		return
+		return false, false
 	}
+	return mine, true
+}
 
-	wrt.Header().Set("Content-Type", "application/json")
-	wrt.WriteHeader(http.StatusOK)
-	errJSON := json.NewEncoder(wrt).Encode(cards)
-	if errJSON != nil {
-		log.Printf("error while sending response body: %v\n", errJSON)
+// parseDateParam разбирает дату в формате RFC 3339 или YYYY-MM-DD.
+// В случае ошибки отправляет сообщение об ошибке и возвращает false
+func parseDateParam(wrt http.ResponseWriter, dateParam, name string) (*time.Time, bool) {
+	if dateParam == "" {
+		return nil, true
 	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if date, err := time.Parse(layout, dateParam); err == nil {
+			return &date, true
+		}
+	}
+
+	errSend := handlers.SendBadReq(wrt, fmt.Sprintf("ошибка: %s должен быть датой в формате RFC 3339 или YYYY-MM-DD", name))
+	if errSend != nil {
+		log.Printf("error while sending the bad request message: %v\n", errSend)
+	}
+	return nil, false
+}
+
+// parseTags разбирает список тегов, разделенных запятыми
+func parseTags(tagsParam string) []string {
+	return tags.Normalize(strings.Split(tagsParam, ","))
 }
@@ -9,8 +9,12 @@ import (
 	ihd "marketplace/internal/handlers/images"
 	uhd "marketplace/internal/handlers/user"
 	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/mux"
@@ -21,6 +25,7 @@ func setupTestServerForGetCards(t *testing.T) (*httptest.Server, *uhd.UserHandle
 	if err != nil {
 		t.Fatalf("error while connecting to the database: %v", err)
 	}
+	ssn := sessions.NewDBRepo(dtb)
 
 	userHandler := GetUserHandler(t)
 	var ihr = GetImagesHandler(t)
@@ -28,8 +33,8 @@ func setupTestServerForGetCards(t *testing.T) (*httptest.Server, *uhd.UserHandle
 	rtr := mux.NewRouter()
 	rtr.HandleFunc("/sign-in", userHandler.SignIn).Methods("POST")
 	rtr.HandleFunc("/sign-up", userHandler.SignUp).Methods("POST")
-	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(userHandler.PostACard, dtb, true)).Methods("POST")
-	rtr.HandleFunc("/get-cards", middleware.RequireAuth(userHandler.GetCards, dtb, false)).Methods("GET")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(userHandler.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(userHandler.GetCards, ssn, false)).Methods("GET")
 	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
 	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
 	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
@@ -59,6 +64,38 @@ var testsForAuthorized = map[string]struct {
 			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3", IsOwned: true},
 			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3", IsOwned: true},
 		}},
+	"url №3: /get-cards?q=text9&sort_by=relevance": {input: "/get-cards?q=text9&sort_by=relevance",
+		result: []cards.CardOutput{
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3", IsOwned: true},
+		}},
+	"url №4: /get-cards?category=3&sort_by=price&order=asc": {input: "/get-cards?category=3&sort_by=price&order=asc",
+		result: []cards.CardOutput{
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3", IsOwned: true},
+			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3", IsOwned: true},
+		}},
+	"url №4.1: /get-cards?category=1&sort_by=price&order=asc": {input: "/get-cards?category=1&sort_by=price&order=asc",
+		result: []cards.CardOutput{
+			{Title: "title5", Text: "text5", ImageURL: "https://www.example.com/images/image5.jpg", Price: 5000, Username: "user1", IsOwned: false},
+			{Title: "title6", Text: "text6", ImageURL: "https://www.example.com/images/image6.jpg", Price: 6000, Username: "user1", IsOwned: false},
+			{Title: "title7", Text: "text7", ImageURL: "https://www.example.com/images/image7.jpg", Price: 7000, Username: "user1", IsOwned: false},
+			{Title: "title8", Text: "text8", ImageURL: "https://www.example.com/images/image8.jpg", Price: 8000, Username: "user1", IsOwned: false},
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3", IsOwned: true},
+			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3", IsOwned: true},
+		}},
+	"url №5: /get-cards?tags=phone,new": {input: "/get-cards?tags=phone,new",
+		result: []cards.CardOutput{
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3", IsOwned: true},
+		}},
+	"url №6: /get-cards?any_tags=new,phone": {input: "/get-cards?any_tags=new,phone",
+		result: []cards.CardOutput{
+			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3", IsOwned: true},
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3", IsOwned: true},
+		}},
+	"url №7: /get-cards?author=user3&created_after=2000-01-01": {input: "/get-cards?author=user3&created_after=2000-01-01",
+		result: []cards.CardOutput{
+			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3", IsOwned: true},
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3", IsOwned: true},
+		}},
 }
 
 var testsForUnauthorized = map[string]struct {
@@ -80,6 +117,38 @@ var testsForUnauthorized = map[string]struct {
 			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3"},
 			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3"},
 		}},
+	"url №3: /get-cards?q=text9&sort_by=relevance": {input: "/get-cards?q=text9&sort_by=relevance",
+		result: []cards.CardOutput{
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3"},
+		}},
+	"url №4: /get-cards?category=3&sort_by=price&order=asc": {input: "/get-cards?category=3&sort_by=price&order=asc",
+		result: []cards.CardOutput{
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3"},
+			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3"},
+		}},
+	"url №4.1: /get-cards?category=1&sort_by=price&order=asc": {input: "/get-cards?category=1&sort_by=price&order=asc",
+		result: []cards.CardOutput{
+			{Title: "title5", Text: "text5", ImageURL: "https://www.example.com/images/image5.jpg", Price: 5000, Username: "user1"},
+			{Title: "title6", Text: "text6", ImageURL: "https://www.example.com/images/image6.jpg", Price: 6000, Username: "user1"},
+			{Title: "title7", Text: "text7", ImageURL: "https://www.example.com/images/image7.jpg", Price: 7000, Username: "user1"},
+			{Title: "title8", Text: "text8", ImageURL: "https://www.example.com/images/image8.jpg", Price: 8000, Username: "user1"},
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3"},
+			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3"},
+		}},
+	"url №5: /get-cards?tags=phone,new": {input: "/get-cards?tags=phone,new",
+		result: []cards.CardOutput{
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3"},
+		}},
+	"url №6: /get-cards?any_tags=new,phone": {input: "/get-cards?any_tags=new,phone",
+		result: []cards.CardOutput{
+			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3"},
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3"},
+		}},
+	"url №7: /get-cards?author=user3&created_after=2000-01-01": {input: "/get-cards?author=user3&created_after=2000-01-01",
+		result: []cards.CardOutput{
+			{Title: "title10", Text: "text10", ImageURL: "https://www.example.com/images/image10.jpg", Price: 10000, Username: "user3"},
+			{Title: "title9", Text: "text9", ImageURL: "https://www.example.com/images/image9.jpg", Price: 9000, Username: "user3"},
+		}},
 }
 
 // TestGetCards тестирует сценарий получения ленты объявлений для зарегистрированного пользователя и для незарегистрированного пользователя
@@ -108,10 +177,10 @@ func TestGetCards(t *testing.T) {
 	PrepareTestsForUser1(testsForUnauthorized, imageUrlsForUser1)
 
 	cardsToPost1 := []uhd.PostACardRequest{
-		{Title: "title5", Text: "text5", ImageURL: imageURL5, Price: "5000"},
-		{Title: "title6", Text: "text6", ImageURL: imageURL6, Price: "6000"},
-		{Title: "title7", Text: "text7", ImageURL: imageURL7, Price: "7000"},
-		{Title: "title8", Text: "text8", ImageURL: imageURL8, Price: "8000"},
+		{Title: "title5", Text: "text5", ImageURL: imageURL5, Price: "5000", CategoryID: 2},
+		{Title: "title6", Text: "text6", ImageURL: imageURL6, Price: "6000", CategoryID: 2},
+		{Title: "title7", Text: "text7", ImageURL: imageURL7, Price: "7000", CategoryID: 2},
+		{Title: "title8", Text: "text8", ImageURL: imageURL8, Price: "8000", CategoryID: 2},
 	}
 
 	PostCards(t, ts, cardsToPost1, token)
@@ -135,8 +204,8 @@ func TestGetCards(t *testing.T) {
 	PrepareTestsForUser3(testsForUnauthorized, imageUrlsForUser3)
 
 	cardsToPost2 := []uhd.PostACardRequest{
-		{Title: "title9", Text: "text9", ImageURL: imageURL9, Price: "9000"},
-		{Title: "title10", Text: "text10", ImageURL: imageURL10, Price: "10000"},
+		{Title: "title9", Text: "text9", ImageURL: imageURL9, Price: "9000", CategoryID: 3, Tags: []string{"Phone", "new"}},
+		{Title: "title10", Text: "text10", ImageURL: imageURL10, Price: "10000", CategoryID: 3, Tags: []string{"phone"}},
 	}
 
 	PostCards(t, ts, cardsToPost2, token)
@@ -292,3 +361,153 @@ func Authorize(t *testing.T, ts *httptest.Server, auth uhd.AuthRequest, path str
 	resp.Body.Close()
 	return token
 }
+
+// TestGetCardsByCursor тестирует постраничное получение ленты объявлений по курсору
+func TestGetCardsByCursor(t *testing.T) {
+	ts, uhr := setupTestServerForGetCards(t)
+	auth := uhd.AuthRequest{Username: "user11", Password: "Curs0r_Pag3s!"}
+	token := Authorize(t, ts, auth, "/sign-up")
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+
+	var cardsToPost []uhd.PostACardRequest
+	for idx, price := range []string{"300", "100", "600", "200", "500", "400"} {
+		num := strconv.Itoa(36 + idx)
+		cardsToPost = append(cardsToPost, uhd.PostACardRequest{
+			Title: "title" + num, Text: "text" + num, ImageURL: imageURL, Price: price, CategoryID: 3,
+		})
+	}
+	PostCards(t, ts, cardsToPost, token)
+
+	tests := map[string]struct {
+		query    string
+		expected [][]string
+	}{
+		"по дате создания": {query: "author=user11&per_page=4",
+			expected: [][]string{{"title41", "title40", "title39", "title38"}, {"title37", "title36"}}},
+		"по цене": {query: "author=user11&sort_by=price&order=asc&per_page=4",
+			expected: [][]string{{"title37", "title39", "title36", "title41"}, {"title40", "title38"}}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := "/get-cards?" + test.query + "&cursor="
+			for pageNum, titles := range test.expected {
+				resp, err := http.Get(ts.URL + path)
+				if err != nil {
+					t.Fatalf("failed to issue a GET request: %v", err)
+				}
+
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+				}
+
+				var page uhd.CardsPageResponse
+				if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+					t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+				}
+				resp.Body.Close()
+
+				if len(page.Items) != len(titles) {
+					t.Fatalf("страница %d: ожидалось %d объявлений, но получено %d", pageNum+1, len(titles), len(page.Items))
+				}
+				for i, title := range titles {
+					if page.Items[i].Title != title {
+						t.Errorf("страница %d: ожидался Title: %q, но получен Title: %q", pageNum+1, title, page.Items[i].Title)
+					}
+				}
+
+				isLast := pageNum == len(test.expected)-1
+				if isLast != (page.NextCursor == nil) {
+					t.Fatalf("страница %d: неожиданное значение next_cursor", pageNum+1)
+				}
+				if !isLast {
+					path = "/get-cards?" + test.query + "&cursor=" + url.QueryEscape(*page.NextCursor)
+				}
+			}
+		})
+	}
+
+	resp, err := http.Get(ts.URL + "/get-cards?cursor=invalid")
+	if err != nil {
+		t.Fatalf("failed to issue a GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	HandleBadReq(t, resp, "ошибка: некорректный курсор")
+}
+
+// TestGetCardsEnvelope тестирует получение ленты объявлений с общим количеством объявлений и заголовком Link
+func TestGetCardsEnvelope(t *testing.T) {
+	ts, _ := setupTestServerForGetCards(t)
+
+	resp, err := http.Get(ts.URL + "/get-cards?price_min=5000&price_max=10000&per_page=4&page=1&envelope=true")
+	if err != nil {
+		t.Fatalf("failed to issue a GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var page uhd.CardsEnvelopeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+
+	if page.Total != 6 || page.Page != 1 || page.PerPage != 4 || !page.HasNext || len(page.Items) != 4 {
+		t.Errorf("Получена неожиданная страница: total=%d page=%d per_page=%d has_next=%t items=%d",
+			page.Total, page.Page, page.PerPage, page.HasNext, len(page.Items))
+	}
+
+	link := resp.Header.Get("Link")
+	if !strings.Contains(link, `rel="next"`) || !strings.Contains(link, "page=2") {
+		t.Errorf("Ожидалась ссылка на следующую страницу в заголовке Link, но получено: %q", link)
+	}
+	if strings.Contains(link, `rel="prev"`) {
+		t.Errorf("Не ожидалась ссылка на предыдущую страницу в заголовке Link: %q", link)
+	}
+}
+
+var testsFeedBadRequest = map[string]struct {
+	input  string
+	result string
+}{
+	"некорректный price_min":      {input: "/get-cards?price_min=abc", result: "ошибка: price_min должен быть числом"},
+	"некорректный price_max":      {input: "/get-cards?price_max=1e", result: "ошибка: price_max должен быть числом"},
+	"некорректный created_after":  {input: "/get-cards?created_after=yesterday", result: "ошибка: created_after должен быть датой в формате RFC 3339 или YYYY-MM-DD"},
+	"некорректный created_before": {input: "/get-cards?created_before=2024-13-01", result: "ошибка: created_before должен быть датой в формате RFC 3339 или YYYY-MM-DD"},
+	"created_after позже created_before": {input: "/get-cards?created_after=2024-02-01&created_before=2024-01-01",
+		result: "ошибка: created_after должен быть раньше created_before"},
+	"created_after равен created_before": {input: "/get-cards?created_after=2024-01-01&created_before=2024-01-01",
+		result: "ошибка: created_after должен быть раньше created_before"},
+	"некорректный mine": {input: "/get-cards?mine=yes", result: "ошибка: mine должен быть логическим значением"},
+	"курсор при сортировке по релевантности": {input: "/get-cards?q=phone&sort_by=relevance&cursor=",
+		result: "ошибка: курсор не поддерживается при сортировке по релевантности"},
+}
+
+// TestGetCardsBadRequest тестирует некорректные параметры запроса ленты объявлений
+func TestGetCardsBadRequest(t *testing.T) {
+	ts, _ := setupTestServerForGetCards(t)
+	for name, test := range testsFeedBadRequest {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			resp, err := http.Get(ts.URL + test.input)
+			if err != nil {
+				t.Fatalf("failed to issue a GET request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			HandleBadReq(t, resp, test.result)
+		})
+	}
+
+	resp, err := http.Get(ts.URL + "/get-cards?mine=true")
+	if err != nil {
+		t.Fatalf("failed to issue a GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+}
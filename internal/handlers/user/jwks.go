@@ -0,0 +1,29 @@
+package user
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/token"
+	"net/http"
+)
+
+// GetJWKS получает открытые ключи проверки access-токенов в формате JWK Set
+func (hnd *UserHandler) GetJWKS(wrt http.ResponseWriter, rqt *http.Request) {
+	jwks, err := token.GetJWKS()
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.Header().Set("Cache-Control", "public, max-age=300")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(jwks)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
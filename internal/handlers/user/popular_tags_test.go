@@ -0,0 +1,103 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	thd "marketplace/internal/handlers/tags"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"marketplace/internal/tags"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForPopularTags(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+	thr := &thd.TagsHandler{TagsRepo: tags.NewDBRepo(dtb)}
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/tags/popular", thr.GetPopular).Methods("GET")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// getPopularTags получает популярные теги
+func getPopularTags(t *testing.T, ts *httptest.Server, path string) []tags.PopularTag {
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("failed to issue a GET request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var popular []tags.PopularTag
+	if err := json.NewDecoder(resp.Body).Decode(&popular); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return popular
+}
+
+// TestPopularTags тестирует получение популярных тегов опубликованных объявлений
+func TestPopularTags(t *testing.T) {
+	ts, uhr := setupTestServerForPopularTags(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{
+		{Title: "title32", Text: "text32", ImageURL: imageURL, Price: "320", CategoryID: 3, Tags: []string{"Popular", "rare"}},
+		{Title: "title33", Text: "text33", ImageURL: imageURL, Price: "330", CategoryID: 3, Tags: []string{"popular"}},
+		{Title: "title34", Text: "text34", ImageURL: imageURL, Price: "340", CategoryID: 3, Tags: []string{" POPULAR "}},
+		{Title: "title35", Text: "text35", ImageURL: imageURL, Price: "350", CategoryID: 3, Tags: []string{"rare"}, Status: cards.StatusDraft},
+	}, sellerToken)
+
+	counts := make(map[string]int)
+	positions := make(map[string]int)
+	for idx, tag := range getPopularTags(t, ts, "/tags/popular?limit=100") {
+		counts[tag.Name] = tag.Count
+		positions[tag.Name] = idx
+	}
+	// Черновик не учитывается в количестве объявлений с тегом
+	if counts["popular"] != 3 || counts["rare"] != 1 {
+		t.Errorf("Ожидалось 3 объявления с тегом popular и 1 с тегом rare, но получено: %v", counts)
+	}
+	if positions["popular"] > positions["rare"] {
+		t.Errorf("Тег popular должен быть выше тега rare: %v", positions)
+	}
+
+	t.Run("ограничение количества тегов", func(t *testing.T) {
+		if popular := getPopularTags(t, ts, "/tags/popular?limit=1"); len(popular) != 1 {
+			t.Errorf("Ожидался 1 тег, но получено: %+v", popular)
+		}
+	})
+
+	t.Run("некорректный limit", func(t *testing.T) {
+		resp, err := http.Get(ts.URL + "/tags/popular?limit=0")
+		if err != nil {
+			t.Fatalf("failed to issue a GET request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: limit должен быть целым числом от 1 до 100")
+	})
+}
@@ -12,10 +12,11 @@ import (
 	"marketplace/internal/cards"
 	"marketplace/internal/handlers"
 	img "marketplace/internal/images"
-	"marketplace/internal/token"
+	"marketplace/internal/tags"
 	"marketplace/internal/utils"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -29,6 +30,12 @@ const (
 	maxTextLen = 4000
 	// maxPriceValue — максимальная цена
 	maxPriceValue float64 = 1000000000000
+	// maxTags — максимальное количество тегов объявления
+	maxTags int = 10
+	// minTagLen — минимальная длина тега
+	minTagLen int = 1
+	// maxTagLen — максимальная длина тега
+	maxTagLen int = 30
 )
 
 // запрос с данными для создания объявления
@@ -41,6 +48,12 @@ type PostACardRequest struct {
 	ImageURL string `json:"image_url"`
 	// Price — цена
 	Price string `json:"price"`
+	// CategoryID — категория
+	CategoryID int `json:"category_id"`
+	// Tags — теги
+	Tags []string `json:"tags,omitempty"`
+	// Status — начальный статус объявления: draft или published (по умолчанию)
+	Status string `json:"status,omitempty"`
 }
 
 // PostACard создает новое объявление
@@ -55,34 +68,18 @@ func (hnd *UserHandler) PostACard(wrt http.ResponseWriter, rqt *http.Request) {
 		return
 	}
 
-	check := utils.CheckLen(prq.Title, "недостаточная", "превышена допустимая", "заголовка", "заголовок", minTitleLen, maxTitleLen)
-	if check != "" {
-		errSend := handlers.SendBadReq(wrt, check)
-		if errSend != nil {
-			log.Printf("error while sending the bad request message: %v\n", errSend)
-		}
-		return
-	}
-
-	check = utils.CheckLen(prq.Title, "недостаточная", "превышена допустимая", "текста объявления", "текст объявления", minTextLen, maxTextLen)
-	if check != "" {
-		errSend := handlers.SendBadReq(wrt, check)
-		if errSend != nil {
-			log.Printf("error while sending the bad request message: %v\n", errSend)
-		}
-		return
+	fields := &EditCardRequest{Title: &prq.Title, Text: &prq.Text, ImageURL: &prq.ImageURL, Price: &prq.Price, CategoryID: &prq.CategoryID}
+	if prq.Tags != nil {
+		fields.Tags = &prq.Tags
 	}
-
-	if err := validatePrice(prq.Price); err != nil {
-		errSend := handlers.SendBadReq(wrt, err.Error())
-		if errSend != nil {
-			log.Printf("error while sending the bad request message: %v\n", errSend)
-		}
+	if !hnd.validateCard(wrt, fields) {
 		return
 	}
 
-	if err := validateImage(prq.ImageURL); err != nil {
-		errSend := handlers.SendBadReq(wrt, err.Error())
+	switch prq.Status {
+	case "", cards.StatusDraft, cards.StatusPublished:
+	default:
+		errSend := handlers.SendBadReq(wrt, "ошибка: новое объявление может иметь только статус draft или published")
 		if errSend != nil {
 			log.Printf("error while sending the bad request message: %v\n", errSend)
 		}
@@ -99,17 +96,13 @@ func (hnd *UserHandler) PostACard(wrt http.ResponseWriter, rqt *http.Request) {
 		return
 	}
 
-	crd := &cards.CardInput{Title: prq.Title, Text: prq.Text, ImageURL: prq.ImageURL, Price: priceFloat64}
-	username, err := token.GetPayload(rqt)
-	if err != nil {
-		errSend := handlers.SendUnauthorized(wrt, err.Error())
-		if errSend != nil {
-			log.Printf("error while sending the unauthorized error message: %v\n", errSend)
-		}
+	crd := &cards.CardInput{Title: prq.Title, Text: prq.Text, ImageURL: prq.ImageURL, Price: priceFloat64, CategoryID: prq.CategoryID, Tags: prq.Tags, Status: prq.Status}
+	_, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
 		return
 	}
 
-	userID, err := hnd.UserRepo.GetUserID(username)
+	card, err := hnd.CardsRepo.PostACard(crd, userID)
 	if err != nil {
 		errSend := handlers.SendInternalServerError(wrt, err.Error())
 		if errSend != nil {
@@ -118,13 +111,8 @@ func (hnd *UserHandler) PostACard(wrt http.ResponseWriter, rqt *http.Request) {
 		return
 	}
 
-	card, err := hnd.CardsRepo.PostACard(crd, userID)
-	if err != nil {
-		errSend := handlers.SendInternalServerError(wrt, err.Error())
-		if errSend != nil {
-			log.Printf("error while sending the internal server error message: %v\n", errSend)
-		}
-		return
+	if card.Status == cards.StatusPublished {
+		hnd.notifySavedSearches(card.ID, userID)
 	}
 
 	wrt.Header().Set("Content-Type", "application/json")
@@ -135,6 +123,115 @@ func (hnd *UserHandler) PostACard(wrt http.ResponseWriter, rqt *http.Request) {
 	}
 }
 
+// notifySavedSearches уведомляет владельцев сохраненных поисков о новом опубликованном объявлении.
+// Ошибка не мешает публикации объявления и только записывается в журнал
+func (hnd *UserHandler) notifySavedSearches(cardID, authorID string) {
+	if hnd.SearchesRepo == nil {
+		return
+	}
+
+	_, err := hnd.SearchesRepo.NotifyMatches(cardID, authorID)
+	if err != nil {
+		log.Printf("error while notifying saved searches about the card %s: %v\n", cardID, err)
+	}
+}
+
+// validateCard валидирует заданные поля объявления. Поля, равные nil, не проверяются
+func (hnd *UserHandler) validateCard(wrt http.ResponseWriter, erq *EditCardRequest) bool {
+	if erq.Title != nil {
+		check := utils.CheckLen(*erq.Title, "недостаточная", "превышена допустимая", "заголовка", "заголовок", minTitleLen, maxTitleLen)
+		if check != "" {
+			errSend := handlers.SendBadReq(wrt, check)
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return false
+		}
+	}
+
+	if erq.Text != nil {
+		check := utils.CheckLen(*erq.Text, "недостаточная", "превышена допустимая", "текста объявления", "текст объявления", minTextLen, maxTextLen)
+		if check != "" {
+			errSend := handlers.SendBadReq(wrt, check)
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return false
+		}
+	}
+
+	if erq.Price != nil {
+		if err := validatePrice(*erq.Price); err != nil {
+			errSend := handlers.SendBadReq(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return false
+		}
+	}
+
+	if erq.CategoryID != nil {
+		exists, err := hnd.CategoriesRepo.Exists(*erq.CategoryID)
+		if err != nil {
+			errSend := handlers.SendInternalServerError(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the internal server error message: %v\n", errSend)
+			}
+			return false
+		}
+
+		if !exists {
+			errSend := handlers.SendBadReq(wrt, "ошибка: категория не существует")
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return false
+		}
+	}
+
+	if erq.Tags != nil {
+		normalized, err := validateTags(*erq.Tags)
+		if err != nil {
+			errSend := handlers.SendBadReq(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return false
+		}
+		*erq.Tags = normalized
+	}
+
+	if erq.ImageURL != nil {
+		if err := validateImage(*erq.ImageURL); err != nil {
+			errSend := handlers.SendBadReq(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// validateTags нормализует теги и валидирует их
+func validateTags(rawTags []string) ([]string, error) {
+	normalized := tags.Normalize(rawTags)
+	for _, tag := range normalized {
+		check := utils.CheckLen(tag, "недостаточная", "превышена допустимая", "тега", "тег", minTagLen, maxTagLen)
+		if check != "" {
+			return nil, fmt.Errorf("%s", check)
+		}
+		if strings.ContainsRune(tag, ',') {
+			return nil, fmt.Errorf("ошибка: тег не может содержать запятую")
+		}
+	}
+
+	if len(normalized) > maxTags {
+		return nil, fmt.Errorf("ошибка: у объявления может быть не более %d тегов", maxTags)
+	}
+	return normalized, nil
+}
+
 // validatePrice валидирует цену
 func validatePrice(priceStr string) error {
 	price, err := strconv.ParseFloat(priceStr, 64)
@@ -9,6 +9,7 @@ import (
 	ihd "marketplace/internal/handlers/images"
 	uhd "marketplace/internal/handlers/user"
 	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -21,12 +22,13 @@ func setupTestServerForPostACard(t *testing.T) (*httptest.Server, *uhd.UserHandl
 	if err != nil {
 		log.Fatalf("error while connecting to the database: %v", err)
 	}
+	ssn := sessions.NewDBRepo(dtb)
 
 	var uhr = GetUserHandler(t)
 	var ihr = GetImagesHandler(t)
 	rtr := mux.NewRouter()
 	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
-	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, dtb, true)).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
 	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
 	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
 	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
@@ -62,7 +64,7 @@ func TestPostACard(t *testing.T) {
 		token := resp.Header.Get("Authorization")
 
 		imageURL := getImageURL(t, ts, uhr, auth.Username)
-		card := uhd.PostACardRequest{Title: "title1", Text: "text1", ImageURL: imageURL, Price: "1000"}
+		card := uhd.PostACardRequest{Title: "title1", Text: "text1", ImageURL: imageURL, Price: "1000", CategoryID: 2}
 		data, err = json.Marshal(card)
 		if err != nil {
 			t.Fatalf("Ошибка сериализации тела запроса клиента: %v", err)
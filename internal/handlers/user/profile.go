@@ -0,0 +1,121 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"marketplace/internal/handlers"
+	ihd "marketplace/internal/handlers/images"
+	"marketplace/internal/user"
+	"marketplace/internal/utils"
+	"net/http"
+	"regexp"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// minDisplayNameLen — минимальная длина отображаемого имени
+	minDisplayNameLen int = 1
+	// maxDisplayNameLen — максимальная длина отображаемого имени
+	maxDisplayNameLen int = 50
+	// maxBioLen — максимальная длина информации о себе
+	maxBioLen int = 500
+)
+
+// avatarImageRE — имя изображения, полученное при загрузке в POST /images
+var avatarImageRE = regexp.MustCompile(fmt.Sprintf("^image%s$", ihd.UUIDRE))
+
+// UpdateProfileRequest — запрос на изменение профиля. Отсутствующие поля не изменяются,
+// пустая строка сбрасывает отображаемое имя или аватар
+type UpdateProfileRequest struct {
+	// DisplayName — отображаемое имя
+	DisplayName *string `json:"display_name"`
+	// Bio — информация о себе
+	Bio *string `json:"bio"`
+	// AvatarImage — имя загруженного изображения аватара
+	AvatarImage *string `json:"avatar_image"`
+}
+
+// GetProfile получает публичный профиль пользователя
+func (hnd *UserHandler) GetProfile(wrt http.ResponseWriter, rqt *http.Request) {
+	username := mux.Vars(rqt)["username"]
+	hnd.sendProfile(wrt, username)
+}
+
+// UpdateProfile изменяет профиль текущего пользователя
+func (hnd *UserHandler) UpdateProfile(wrt http.ResponseWriter, rqt *http.Request) {
+	var urq UpdateProfileRequest
+	err := json.NewDecoder(rqt.Body).Decode(&urq)
+	if err != nil {
+		errSend := handlers.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	if errStr := validateProfile(&urq); errStr != "" {
+		errSend := handlers.SendBadReq(wrt, errStr)
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return
+	}
+
+	username, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	upd := &user.ProfileUpdate{DisplayName: urq.DisplayName, Bio: urq.Bio, AvatarImage: urq.AvatarImage}
+	code, err := hnd.UserRepo.UpdateProfile(userID, upd)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	hnd.sendProfile(wrt, username)
+}
+
+// validateProfile валидирует изменяемые поля профиля. Возвращает текст ошибки или пустую строку
+func validateProfile(urq *UpdateProfileRequest) string {
+	if urq.DisplayName != nil && *urq.DisplayName != "" {
+		errStr := utils.CheckLen(*urq.DisplayName, "недостаточная", "превышена допустимая", "отображаемого имени", "отображаемое имя", minDisplayNameLen, maxDisplayNameLen)
+		if errStr != "" {
+			return errStr
+		}
+	}
+	if urq.Bio != nil {
+		errStr := utils.CheckLen(*urq.Bio, "недостаточная", "превышена допустимая", "информации о себе", "информация о себе", 0, maxBioLen)
+		if errStr != "" {
+			return errStr
+		}
+	}
+	if urq.AvatarImage != nil && *urq.AvatarImage != "" && !avatarImageRE.MatchString(*urq.AvatarImage) {
+		return "неправильное имя изображения"
+	}
+	return ""
+}
+
+// sendProfile отправляет публичный профиль пользователя
+func (hnd *UserHandler) sendProfile(wrt http.ResponseWriter, username string) {
+	profile, code, err := hnd.UserRepo.GetProfile(username)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(profile)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
@@ -0,0 +1,111 @@
+package user
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/token"
+	"net/http"
+)
+
+// RefreshTokenHeader — заголовок ответа с refresh-токеном при входе и регистрации
+const RefreshTokenHeader string = "X-Refresh-Token"
+
+// RefreshRequest — запрос с refresh-токеном
+type RefreshRequest struct {
+	// RefreshToken — refresh-токен
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse — новая пара токенов
+type TokenResponse struct {
+	// AccessToken — access-токен
+	AccessToken string `json:"access_token"`
+	// RefreshToken — refresh-токен, заменяющий предъявленный
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn — срок действия access-токена в секундах
+	ExpiresIn int `json:"expires_in"`
+}
+
+// decodeRefreshRequest разбирает запрос с refresh-токеном.
+// В случае ошибки отправляет сообщение об ошибке и возвращает false
+func decodeRefreshRequest(wrt http.ResponseWriter, rqt *http.Request) (string, bool) {
+	var rrq RefreshRequest
+	err := json.NewDecoder(rqt.Body).Decode(&rrq)
+	if err != nil {
+		errSend := hdr.SendBadReq(wrt, "wrong request body")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return "", false
+	}
+
+	if rrq.RefreshToken == "" {
+		errSend := hdr.SendBadReq(wrt, "ошибка: пользователь не отправил refresh-токен")
+		if errSend != nil {
+			log.Printf("error while sending the bad request message: %v\n", errSend)
+		}
+		return "", false
+	}
+	return rrq.RefreshToken, true
+}
+
+// RefreshToken обменивает refresh-токен на новую пару токенов
+func (hnd *UserHandler) RefreshToken(wrt http.ResponseWriter, rqt *http.Request) {
+	refreshToken, ok := decodeRefreshRequest(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	rotation, code, err := hnd.SessionsRepo.RotateRefreshToken(refreshToken)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	accessToken, err := token.CreateJWTtoken(rotation.UserID, rotation.Username, rotation.SessionID)
+	if err != nil {
+		errSend := hdr.SendInternalServerError(wrt, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	resp := TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: rotation.RefreshToken,
+		ExpiresIn:    int(token.AccessTokenTTL.Seconds()),
+	}
+
+	wrt.Header().Set("Authorization", accessToken)
+	wrt.Header().Set(RefreshTokenHeader, rotation.RefreshToken)
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(resp)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// SignOut завершает сессию, которой принадлежит refresh-токен
+func (hnd *UserHandler) SignOut(wrt http.ResponseWriter, rqt *http.Request) {
+	refreshToken, ok := decodeRefreshRequest(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	code, err := hnd.SessionsRepo.RevokeByRefreshToken(refreshToken)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
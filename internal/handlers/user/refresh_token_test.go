@@ -0,0 +1,139 @@
+package user_test
+
+import (
+	"bytes"
+	"encoding/json"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/token"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForRefresh(t *testing.T) *httptest.Server {
+	uhr := GetUserHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/token/refresh", uhr.RefreshToken).Methods("POST")
+	rtr.HandleFunc("/sign-out", uhr.SignOut).Methods("POST")
+	rtr.HandleFunc("/.well-known/jwks.json", uhr.GetJWKS).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// signIn авторизует пользователя и возвращает refresh-токен
+func signIn(t *testing.T, ts *httptest.Server, auth uhd.AuthRequest) string {
+	data, err := json.Marshal(auth)
+	if err != nil {
+		t.Fatalf("Ошибка сериализации тела запроса клиента: %v", err)
+	}
+
+	resp, err := http.Post(ts.URL+"/sign-in", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to issue a POST request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if resp.Header.Get("Authorization") == "" || resp.Header.Get(uhd.RefreshTokenHeader) == "" {
+		t.Fatalf("Ожидались access-токен и refresh-токен в заголовках ответа")
+	}
+	return resp.Header.Get(uhd.RefreshTokenHeader)
+}
+
+// refreshTokens обменивает refresh-токен на новую пару токенов
+func refreshTokens(t *testing.T, ts *httptest.Server, refreshToken string) (*uhd.TokenResponse, int) {
+	resp := DoJSON(t, http.MethodPost, ts.URL+"/token/refresh", "", uhd.RefreshRequest{RefreshToken: refreshToken})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode
+	}
+
+	var tokens uhd.TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return &tokens, resp.StatusCode
+}
+
+// TestRefreshToken тестирует обмен refresh-токена, обнаружение его повторного использования и выход
+func TestRefreshToken(t *testing.T) {
+	ts := setupTestServerForRefresh(t)
+	auth := uhd.AuthRequest{Username: "user3", Password: "Q#_~s1o!m+B&t/9j0g{"}
+	firstToken := signIn(t, ts, auth)
+
+	t.Run("запрос без refresh-токена", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, ts.URL+"/token/refresh", "", uhd.RefreshRequest{})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: пользователь не отправил refresh-токен")
+	})
+
+	t.Run("недействительный refresh-токен", func(t *testing.T) {
+		if _, code := refreshTokens(t, ts, "invalid"); code != http.StatusUnauthorized {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, code)
+		}
+	})
+
+	tokens, code := refreshTokens(t, ts, firstToken)
+	if code != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, code)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.RefreshToken == firstToken || tokens.ExpiresIn <= 0 {
+		t.Fatalf("Получена неожиданная пара токенов: %+v", tokens)
+	}
+	secondToken := tokens.RefreshToken
+
+	t.Run("повторное использование refresh-токена", func(t *testing.T) {
+		if _, code := refreshTokens(t, ts, firstToken); code != http.StatusUnauthorized {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, code)
+		}
+		if _, code := refreshTokens(t, ts, secondToken); code != http.StatusUnauthorized {
+			t.Fatalf("Ожидался отзыв всего семейства токенов, но получен код состояния ответа: %d", code)
+		}
+	})
+
+	t.Run("выход", func(t *testing.T) {
+		refreshToken := signIn(t, ts, auth)
+
+		resp := DoJSON(t, http.MethodPost, ts.URL+"/sign-out", "", uhd.RefreshRequest{RefreshToken: refreshToken})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+		}
+
+		if _, code := refreshTokens(t, ts, refreshToken); code != http.StatusUnauthorized {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, code)
+		}
+	})
+
+	t.Run("открытые ключи проверки токена", func(t *testing.T) {
+		parsed, _, err := jwt.NewParser().ParseUnverified(tokens.AccessToken, jwt.MapClaims{})
+		if err != nil {
+			t.Fatalf("Ошибка разбора токена: %v", err)
+		}
+
+		resp := DoJSON(t, http.MethodGet, ts.URL+"/.well-known/jwks.json", "", nil)
+		defer resp.Body.Close()
+		var jwks token.JWKSet
+		if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+			t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+		}
+
+		found := false
+		for _, key := range jwks.Keys {
+			found = found || (key.Kid == parsed.Header["kid"] && key.Alg == parsed.Method.Alg())
+		}
+		if !found {
+			t.Errorf("Ключ токена %v не найден в наборе открытых ключей: %+v", parsed.Header, jwks)
+		}
+	})
+}
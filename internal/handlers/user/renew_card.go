@@ -0,0 +1,36 @@
+package user
+
+import (
+	"encoding/json"
+	"log"
+	"marketplace/internal/handlers"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RenewCard продлевает срок жизни объявления текущего пользователя
+func (hnd *UserHandler) RenewCard(wrt http.ResponseWriter, rqt *http.Request) {
+	cardID := mux.Vars(rqt)["id"]
+
+	username, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	card, code, err := hnd.CardsRepo.RenewCard(cardID, userID, username)
+	if code != handlers.OKCode {
+		errSend := handlers.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(card)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
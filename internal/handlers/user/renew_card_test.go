@@ -0,0 +1,134 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForRenew(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.GetCard, ssn, false)).Methods("GET")
+	rtr.HandleFunc(cardPath+"/status", middleware.RequireAuth(uhr.ChangeStatus, ssn, true)).Methods("PATCH")
+	rtr.HandleFunc(cardPath+"/renew", middleware.RequireAuth(uhr.RenewCard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// renewCard продлевает объявление и проверяет код состояния ответа
+func renewCard(t *testing.T, ts *httptest.Server, cardID, token string, expectedCode int) cards.CardOutput {
+	resp := DoJSON(t, http.MethodPost, ts.URL+"/cards/"+cardID+"/renew", token, nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedCode {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", expectedCode, resp.StatusCode)
+	}
+
+	var card cards.CardOutput
+	if expectedCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+			t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+		}
+	}
+	return card
+}
+
+// inFeed проверяет, есть ли объявление в публичной ленте
+func inFeed(t *testing.T, ts *httptest.Server, cardID string) bool {
+	for _, card := range GetFeed(t, ts, "/get-cards?per_page=100", "") {
+		if card.ID == cardID {
+			return true
+		}
+	}
+	return false
+}
+
+// TestRenewCard тестирует архивирование истекших объявлений и их продление
+func TestRenewCard(t *testing.T) {
+	ts, uhr := setupTestServerForRenew(t)
+	dtb := ConnectToDB(t)
+	auth := uhd.AuthRequest{Username: "user3", Password: "Q#_~s1o!m+B&t/9j0g{"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+	otherToken := Authorize(t, ts, uhd.AuthRequest{Username: "user5", Password: "B^y3r_Pa55word"}, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	expiredID := postAndFindCard(t, ts, imageURL, sellerToken, "title28")
+	archivedID := postAndFindCard(t, ts, imageURL, sellerToken, "title29")
+
+	_, err := dtb.Exec("UPDATE cards SET expires_at = NOW() - INTERVAL '1 day' WHERE id = $1;", expiredID)
+	if err != nil {
+		t.Fatalf("Ошибка изменения срока жизни объявления: %v", err)
+	}
+
+	t.Run("истекшее объявление не показывается в ленте", func(t *testing.T) {
+		if inFeed(t, ts, expiredID) {
+			t.Errorf("Истекшее объявление %s найдено в ленте", expiredID)
+		}
+
+		resp := DoJSON(t, http.MethodGet, ts.URL+"/cards/"+expiredID, otherToken, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNotFound, resp.StatusCode)
+		}
+	})
+
+	count, err := cards.NewDBRepo(dtb).ArchiveExpired()
+	if err != nil || count < 1 {
+		t.Fatalf("Ожидалось архивирование истекшего объявления, но получено: %d, %v", count, err)
+	}
+	checkCardStatus(t, ts, expiredID, sellerToken, cards.StatusArchived)
+	checkCardStatus(t, ts, archivedID, sellerToken, cards.StatusPublished)
+
+	t.Run("продление чужого объявления", func(t *testing.T) {
+		renewCard(t, ts, expiredID, otherToken, http.StatusForbidden)
+	})
+
+	renewed := renewCard(t, ts, expiredID, sellerToken, http.StatusOK)
+	if renewed.Status != cards.StatusPublished || !renewed.ExpiresAt.After(time.Now()) {
+		t.Fatalf("Ожидалась повторная публикация объявления, но получено: %+v", renewed)
+	}
+	if !inFeed(t, ts, expiredID) {
+		t.Errorf("Продленное объявление %s не найдено в ленте", expiredID)
+	}
+
+	t.Run("продление объявления, архивированного автором", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPatch, ts.URL+"/cards/"+archivedID+"/status", sellerToken,
+			uhd.ChangeStatusRequest{Status: cards.StatusArchived})
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+		}
+
+		_, err := dtb.Exec("UPDATE cards SET expires_at = NOW() - INTERVAL '1 day' WHERE id = $1;", archivedID)
+		if err != nil {
+			t.Fatalf("Ошибка изменения срока жизни объявления: %v", err)
+		}
+		renewCard(t, ts, archivedID, sellerToken, http.StatusConflict)
+		checkCardStatus(t, ts, archivedID, sellerToken, cards.StatusArchived)
+	})
+}
@@ -0,0 +1,214 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	ohd "marketplace/internal/handlers/offers"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/offers"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForOffers(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+	ohr := &ohd.OffersHandler{OffersRepo: offers.NewDBRepo(dtb)}
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/sign-up", uhr.SignUp).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.GetCard, ssn, false)).Methods("GET")
+	rtr.HandleFunc(cardPath+"/offers", middleware.RequireAuth(ohr.MakeOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc(cardPath+"/offers", middleware.RequireAuth(ohr.GetCardOffers, ssn, true)).Methods("GET")
+	offerPath := fmt.Sprintf("/offers/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(offerPath+"/accept", middleware.RequireAuth(ohr.AcceptOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc(offerPath+"/reject", middleware.RequireAuth(ohr.RejectOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc(offerPath+"/counter", middleware.RequireAuth(ohr.CounterOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// TestOffers тестирует сценарий торга: предложение, встречное предложение и его принятие
+func TestOffers(t *testing.T) {
+	ts, uhr := setupTestServerForOffers(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{{Title: "title21", Text: "text21", ImageURL: imageURL, Price: "21000", CategoryID: 3}}, sellerToken)
+
+	feed := GetFeed(t, ts, "/get-cards", sellerToken)
+	if len(feed) == 0 || feed[0].Title != "title21" {
+		t.Fatalf("в ленте объявлений не найдено только что созданное объявление")
+	}
+	cardURL := ts.URL + "/cards/" + feed[0].ID
+
+	buyerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user8", Password: "0ff3r_Buy3r!"}, "/sign-up")
+	otherToken := Authorize(t, ts, uhd.AuthRequest{Username: "user5", Password: "B^y3r_Pa55word"}, "/sign-in")
+
+	t.Run("предложение по своему объявлению", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, cardURL+"/offers", sellerToken, ohd.OfferRequest{Amount: 20000})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: нельзя сделать предложение по своему объявлению")
+	})
+
+	t.Run("предложение выше цены", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, cardURL+"/offers", buyerToken, ohd.OfferRequest{Amount: 25000})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: предложенная цена не может превышать цену объявления")
+	})
+
+	offer := doOffer(t, http.MethodPost, cardURL+"/offers", buyerToken, ohd.OfferRequest{Amount: 15000}, http.StatusOK)
+	if offer.Status != offers.StatusPending || offer.Buyer != "user8" || offer.Seller != "user1" || offer.ProposedBy != "user8" {
+		t.Fatalf("Получено неожиданное предложение: %+v", offer)
+	}
+	offerURL := ts.URL + "/offers/" + offer.ID
+
+	doOffer(t, http.MethodPost, cardURL+"/offers", buyerToken, ohd.OfferRequest{Amount: 16000}, http.StatusConflict)
+	doOffer(t, http.MethodPost, offerURL+"/accept", buyerToken, nil, http.StatusForbidden)
+
+	t.Run("чужие предложения", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodGet, cardURL+"/offers", otherToken, nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusForbidden, resp.StatusCode)
+		}
+	})
+
+	t.Run("встречное предложение выше цены", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, offerURL+"/counter", sellerToken, ohd.OfferRequest{Amount: 25000})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: предложенная цена не может превышать цену объявления")
+	})
+
+	counter := doOffer(t, http.MethodPost, offerURL+"/counter", sellerToken, ohd.OfferRequest{Amount: 18000}, http.StatusOK)
+	if counter.ParentID == nil || *counter.ParentID != offer.ID || counter.ProposedBy != "user1" || counter.Status != offers.StatusPending {
+		t.Fatalf("Получено неожиданное встречное предложение: %+v", counter)
+	}
+	doOffer(t, http.MethodPost, offerURL+"/accept", sellerToken, nil, http.StatusConflict)
+
+	other := doOffer(t, http.MethodPost, cardURL+"/offers", otherToken, ohd.OfferRequest{Amount: 17000}, http.StatusOK)
+
+	dtb := ConnectToDB(t)
+	t.Run("истекшее предложение", func(t *testing.T) {
+		_, err := dtb.Exec("UPDATE offers SET expires_at = NOW() - INTERVAL '1 hour' WHERE id = $1;", other.ID)
+		if err != nil {
+			t.Fatalf("Ошибка изменения срока действия предложения: %v", err)
+		}
+		doOffer(t, http.MethodPost, ts.URL+"/offers/"+other.ID+"/accept", sellerToken, nil, http.StatusConflict)
+
+		_, err = dtb.Exec("UPDATE offers SET expires_at = NOW() + INTERVAL '1 hour' WHERE id = $1;", other.ID)
+		if err != nil {
+			t.Fatalf("Ошибка изменения срока действия предложения: %v", err)
+		}
+	})
+
+	t.Run("принятие предложения по истекшему объявлению", func(t *testing.T) {
+		_, err := dtb.Exec("UPDATE cards SET expires_at = NOW() - INTERVAL '1 day' WHERE id = $1;", feed[0].ID)
+		if err != nil {
+			t.Fatalf("Ошибка изменения срока жизни объявления: %v", err)
+		}
+		doOffer(t, http.MethodPost, ts.URL+"/offers/"+counter.ID+"/accept", buyerToken, nil, http.StatusConflict)
+
+		_, err = dtb.Exec("UPDATE cards SET expires_at = NOW() + INTERVAL '30 days' WHERE id = $1;", feed[0].ID)
+		if err != nil {
+			t.Fatalf("Ошибка изменения срока жизни объявления: %v", err)
+		}
+	})
+
+	accepted := doOffer(t, http.MethodPost, ts.URL+"/offers/"+counter.ID+"/accept", buyerToken, nil, http.StatusOK)
+	if accepted.Status != offers.StatusAccepted || accepted.Amount != 18000 {
+		t.Fatalf("Получено неожиданное принятое предложение: %+v", accepted)
+	}
+
+	resp := DoJSON(t, http.MethodGet, cardURL, sellerToken, nil)
+	defer resp.Body.Close()
+	var card cards.CardOutput
+	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	if card.Status != cards.StatusReserved {
+		t.Errorf("Ожидался статус объявления %s, но получен %s", cards.StatusReserved, card.Status)
+	}
+
+	resp = DoJSON(t, http.MethodGet, cardURL+"/offers", sellerToken, nil)
+	defer resp.Body.Close()
+	var offersList []offers.Offer
+	if err := json.NewDecoder(resp.Body).Decode(&offersList); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+
+	statuses := make(map[string]string)
+	for _, ofr := range offersList {
+		statuses[ofr.ID] = ofr.Status
+	}
+	expected := map[string]string{offer.ID: offers.StatusCountered, counter.ID: offers.StatusAccepted, other.ID: offers.StatusRejected}
+	for id, status := range expected {
+		if statuses[id] != status {
+			t.Errorf("Ожидался статус предложения %s: %s, но получен: %s", id, status, statuses[id])
+		}
+	}
+
+	t.Run("истек срок создания заказа по принятому предложению", func(t *testing.T) {
+		_, err := dtb.Exec("UPDATE offers SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1;", counter.ID)
+		if err != nil {
+			t.Fatalf("Ошибка изменения срока действия предложения: %v", err)
+		}
+		if count, err := offers.NewDBRepo(dtb).ExpireOffers(); err != nil || count < 1 {
+			t.Fatalf("Ожидалось истечение принятого предложения, но получено: %d, %v", count, err)
+		}
+
+		resp := DoJSON(t, http.MethodGet, cardURL+"/offers", sellerToken, nil)
+		defer resp.Body.Close()
+		var offersList []offers.Offer
+		if err := json.NewDecoder(resp.Body).Decode(&offersList); err != nil {
+			t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+		}
+		for _, ofr := range offersList {
+			if ofr.ID == counter.ID && ofr.Status != offers.StatusExpired {
+				t.Errorf("Ожидался статус предложения %s, но получен %s", offers.StatusExpired, ofr.Status)
+			}
+		}
+		checkCardStatus(t, ts, feed[0].ID, sellerToken, cards.StatusPublished)
+	})
+}
+
+func doOffer(t *testing.T, method, fullURL, token string, body any, expectedCode int) offers.Offer {
+	resp := DoJSON(t, method, fullURL, token, body)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedCode {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", expectedCode, resp.StatusCode)
+	}
+
+	var offer offers.Offer
+	if expectedCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&offer); err != nil {
+			t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+		}
+	}
+	return offer
+}
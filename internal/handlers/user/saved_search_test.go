@@ -0,0 +1,138 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	nhd "marketplace/internal/handlers/notifications"
+	shd "marketplace/internal/handlers/searches"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/notifications"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForSavedSearches(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+	shr := &shd.SearchesHandler{SearchesRepo: uhr.SearchesRepo, CategoriesRepo: uhr.CategoriesRepo}
+	nhr := &nhd.NotificationsHandler{NotificationsRepo: notifications.NewDBRepo(dtb)}
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/sign-up", uhr.SignUp).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/me/searches", middleware.RequireAuth(shr.SaveSearch, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/me/searches", middleware.RequireAuth(shr.GetSearches, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/me/notifications", middleware.RequireAuth(nhr.GetNotifications, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/me/notifications/read", middleware.RequireAuth(nhr.MarkAllRead, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// TestSavedSearchNotifications тестирует сценарий уведомления о новом объявлении, удовлетворяющем сохраненному поиску
+func TestSavedSearchNotifications(t *testing.T) {
+	ts, uhr := setupTestServerForSavedSearches(t)
+	buyerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user6", Password: "S3arch_f0r-It"}, "/sign-up")
+
+	t.Run("поиск без фильтров", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, ts.URL+"/me/searches", buyerToken, shd.SaveSearchRequest{Name: "все"})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: в сохраненном поиске должен быть задан хотя бы один фильтр")
+	})
+
+	priceMin, priceMax := 99990.0, 99999.0
+	srq := shd.SaveSearchRequest{Name: "дорогие телефоны", Filter: cards.QueryParams{PriceMin: &priceMin, PriceMax: &priceMax}}
+	resp := DoJSON(t, http.MethodPost, ts.URL+"/me/searches", buyerToken, srq)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Категория 1 — родительская категория категории 2
+	categoryID, search := 1, "title31"
+	srq = shd.SaveSearchRequest{Name: "телефоны с чехлом", Filter: cards.QueryParams{
+		CategoryID: &categoryID, Tags: []string{"case"}, AnyTags: []string{"new", "used"}, Search: &search,
+	}}
+	resp = DoJSON(t, http.MethodPost, ts.URL+"/me/searches", buyerToken, srq)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{
+		{Title: "title15", Text: "text15", ImageURL: imageURL, Price: "99995", CategoryID: 2},
+		{Title: "title16", Text: "text16", ImageURL: imageURL, Price: "500", CategoryID: 2},
+		{Title: "title31", Text: "text31", ImageURL: imageURL, Price: "700", CategoryID: 2, Tags: []string{"case", "used"}},
+		{Title: "title31", Text: "text31", ImageURL: imageURL, Price: "700", CategoryID: 2, Tags: []string{"case"}},
+	}, sellerToken)
+
+	ntfs := getNotifications(t, ts, "/me/notifications", buyerToken)
+	if len(ntfs) != 2 {
+		t.Fatalf("Ожидалось 2 уведомления, но получено %d", len(ntfs))
+	}
+	titles := map[string]bool{}
+	for _, ntf := range ntfs {
+		if ntf.Kind != notifications.KindSearchMatch || ntf.CardTitle == nil || ntf.IsRead {
+			t.Fatalf("Получено неожиданное уведомление: %+v", ntf)
+		}
+		titles[*ntf.CardTitle] = true
+	}
+	if !titles["title15"] || !titles["title31"] {
+		t.Errorf("Ожидались уведомления об объявлениях title15 и title31, но получены: %+v", ntfs)
+	}
+
+	t.Run("повторная проверка объявления", func(t *testing.T) {
+		if ntfs[0].CardID == nil {
+			t.Fatalf("В уведомлении нет объявления: %+v", ntfs[0])
+		}
+		created, err := uhr.SearchesRepo.NotifyMatches(*ntfs[0].CardID, uuid.NewString())
+		if err != nil || created != 0 {
+			t.Errorf("Ожидалось 0 новых уведомлений, но получено: %d, %v", created, err)
+		}
+	})
+
+	resp = DoJSON(t, http.MethodPost, ts.URL+"/me/notifications/read", buyerToken, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	if unread := getNotifications(t, ts, "/me/notifications?unread=true", buyerToken); len(unread) != 0 {
+		t.Errorf("Ожидалось 0 непрочитанных уведомлений, но получено %d", len(unread))
+	}
+}
+
+func getNotifications(t *testing.T, ts *httptest.Server, path, token string) []notifications.Notification {
+	resp := DoJSON(t, http.MethodGet, ts.URL+path, token, nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var ntfs []notifications.Notification
+	if err := json.NewDecoder(resp.Body).Decode(&ntfs); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return ntfs
+}
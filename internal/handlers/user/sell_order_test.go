@@ -0,0 +1,322 @@
+package user_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	ohd "marketplace/internal/handlers/offers"
+	ordhd "marketplace/internal/handlers/orders"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/offers"
+	"marketplace/internal/orders"
+	"marketplace/internal/payments"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForOrders(t *testing.T) (*httptest.Server, *uhd.UserHandler, *payments.FakeProvider) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+	prv := payments.NewFakeProvider([]byte("TestWebhookSecret"))
+	ohr := &ordhd.OrdersHandler{OrdersRepo: orders.NewDBRepo(dtb), Payments: prv}
+	ofhr := &ohd.OffersHandler{OffersRepo: offers.NewDBRepo(dtb)}
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/sign-up", uhr.SignUp).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.GetCard, ssn, false)).Methods("GET")
+	rtr.HandleFunc(cardPath, middleware.RequireAuth(uhr.DeleteCard, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc(cardPath+"/status", middleware.RequireAuth(uhr.ChangeStatus, ssn, true)).Methods("PATCH")
+	rtr.HandleFunc(cardPath+"/offers", middleware.RequireAuth(ofhr.MakeOffer, ssn, true)).Methods("POST")
+	offerPath := fmt.Sprintf("/offers/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(offerPath+"/accept", middleware.RequireAuth(ofhr.AcceptOffer, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/orders", middleware.RequireAuth(ohr.CreateOrder, ssn, true)).Methods("POST")
+	orderPath := fmt.Sprintf("/orders/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(orderPath, middleware.RequireAuth(ohr.GetOrder, ssn, true)).Methods("GET")
+	rtr.HandleFunc(orderPath+"/ship", middleware.RequireAuth(ohr.ShipOrder, ssn, true)).Methods("POST")
+	rtr.HandleFunc(orderPath+"/complete", middleware.RequireAuth(ohr.CompleteOrder, ssn, true)).Methods("POST")
+	rtr.HandleFunc(orderPath+"/cancel", middleware.RequireAuth(ohr.CancelOrder, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/payments/webhook", ohr.PaymentWebhook).Methods("POST")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr, prv
+}
+
+// TestOrders тестирует жизненный цикл заказа: создание, оплату, отправку, получение и отмену с возвратом платежа
+func TestOrders(t *testing.T) {
+	ts, uhr, prv := setupTestServerForOrders(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{
+		{Title: "title22", Text: "text22", ImageURL: imageURL, Price: "22000", CategoryID: 3},
+		{Title: "title23", Text: "text23", ImageURL: imageURL, Price: "23000", CategoryID: 3},
+	}, sellerToken)
+
+	feed := GetFeed(t, ts, "/get-cards", sellerToken)
+	if len(feed) < 2 || feed[0].Title != "title23" || feed[1].Title != "title22" {
+		t.Fatalf("в ленте объявлений не найдены только что созданные объявления")
+	}
+	cancelledCardID, cardID := feed[0].ID, feed[1].ID
+
+	buyerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user9", Password: "0rd3r_Buy3r!"}, "/sign-up")
+
+	t.Run("заказ без объявления", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: должен быть задан либо card_id, либо offer_id")
+	})
+
+	t.Run("заказ своего объявления", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, ts.URL+"/orders", sellerToken, ordhd.CreateOrderRequest{CardID: cardID})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: нельзя купить свое объявление")
+	})
+
+	order := doOrder(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{CardID: cardID}, http.StatusOK)
+	if order.Status != orders.StatusPending || order.Amount != 22000 || order.PaymentID == nil || order.PaymentURL == nil {
+		t.Fatalf("Получен неожиданный заказ: %+v", order)
+	}
+	checkCardStatus(t, ts, cardID, sellerToken, cards.StatusReserved)
+	orderURL := ts.URL + "/orders/" + order.ID
+
+	t.Run("изменение статуса объявления при незавершенном заказе", func(t *testing.T) {
+		for _, status := range []string{cards.StatusPublished, cards.StatusSold} {
+			resp := DoJSON(t, http.MethodPatch, ts.URL+"/cards/"+cardID+"/status", sellerToken, uhd.ChangeStatusRequest{Status: status})
+			resp.Body.Close()
+
+			if resp.StatusCode != http.StatusConflict {
+				t.Errorf("%s: ожидался код состояния ответа: %d, но получен: %d", status, http.StatusConflict, resp.StatusCode)
+			}
+		}
+		checkCardStatus(t, ts, cardID, sellerToken, cards.StatusReserved)
+	})
+
+	doOrder(t, http.MethodPost, orderURL+"/ship", sellerToken, nil, http.StatusConflict)
+
+	body, signature, err := prv.Complete(*order.PaymentID, payments.StatusSucceeded)
+	if err != nil {
+		t.Fatalf("Ошибка проведения платежа: %v", err)
+	}
+	sendWebhook(t, ts, body, prv.Sign([]byte("forged")), http.StatusUnauthorized)
+	sendWebhook(t, ts, body, signature, http.StatusNoContent)
+	sendWebhook(t, ts, body, signature, http.StatusNoContent)
+
+	if paid := doOrder(t, http.MethodGet, orderURL, buyerToken, nil, http.StatusOK); paid.Status != orders.StatusPaid {
+		t.Fatalf("Ожидался статус заказа %s, но получен %s", orders.StatusPaid, paid.Status)
+	}
+
+	doOrder(t, http.MethodPost, orderURL+"/ship", buyerToken, nil, http.StatusConflict)
+	doOrder(t, http.MethodPost, orderURL+"/ship", sellerToken, nil, http.StatusOK)
+	completed := doOrder(t, http.MethodPost, orderURL+"/complete", buyerToken, nil, http.StatusOK)
+	if completed.Status != orders.StatusCompleted {
+		t.Fatalf("Ожидался статус заказа %s, но получен %s", orders.StatusCompleted, completed.Status)
+	}
+	checkCardStatus(t, ts, cardID, sellerToken, cards.StatusSold)
+
+	t.Run("удаление объявления с заказом", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodDelete, ts.URL+"/cards/"+cardID, sellerToken, nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusConflict {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusConflict, resp.StatusCode)
+		}
+		doOrder(t, http.MethodGet, orderURL, buyerToken, nil, http.StatusOK)
+	})
+
+	t.Run("отмена оплаченного заказа", func(t *testing.T) {
+		order := doOrder(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{CardID: cancelledCardID}, http.StatusOK)
+		body, signature, err := prv.Complete(*order.PaymentID, payments.StatusSucceeded)
+		if err != nil {
+			t.Fatalf("Ошибка проведения платежа: %v", err)
+		}
+		sendWebhook(t, ts, body, signature, http.StatusNoContent)
+
+		orderURL := ts.URL + "/orders/" + order.ID
+		doOrder(t, http.MethodPost, orderURL+"/cancel", buyerToken, nil, http.StatusConflict)
+		if prv.IsRefunded(*order.PaymentID) {
+			t.Fatalf("Платеж возвращен при недопустимой отмене заказа")
+		}
+
+		cancelled := doOrder(t, http.MethodPost, orderURL+"/cancel", sellerToken, nil, http.StatusOK)
+		if cancelled.Status != orders.StatusCancelled || !prv.IsRefunded(*order.PaymentID) ||
+			cancelled.RefundStatus == nil || *cancelled.RefundStatus != orders.RefundRefunded {
+			t.Errorf("Ожидалась отмена заказа с возвратом платежа, но получено: %+v", cancelled)
+		}
+		checkCardStatus(t, ts, cancelledCardID, sellerToken, cards.StatusPublished)
+	})
+
+	t.Run("неуспешная оплата", func(t *testing.T) {
+		cardID := postAndFindCard(t, ts, imageURL, sellerToken, "title26")
+		order := doOrder(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{CardID: cardID}, http.StatusOK)
+		body, signature, err := prv.Complete(*order.PaymentID, payments.StatusFailed)
+		if err != nil {
+			t.Fatalf("Ошибка проведения платежа: %v", err)
+		}
+		sendWebhook(t, ts, body, signature, http.StatusNoContent)
+		sendWebhook(t, ts, body, signature, http.StatusNoContent)
+
+		if failed := doOrder(t, http.MethodGet, ts.URL+"/orders/"+order.ID, buyerToken, nil, http.StatusOK); failed.Status != orders.StatusCancelled {
+			t.Errorf("Ожидался статус заказа %s, но получен %s", orders.StatusCancelled, failed.Status)
+		}
+		checkCardStatus(t, ts, cardID, sellerToken, cards.StatusPublished)
+	})
+
+	t.Run("истек срок оплаты", func(t *testing.T) {
+		cardID := postAndFindCard(t, ts, imageURL, sellerToken, "title27")
+		order := doOrder(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{CardID: cardID}, http.StatusOK)
+		if !order.ExpiresAt.After(order.CreatedAt) {
+			t.Fatalf("Ожидался срок оплаты позже даты создания заказа: %+v", order)
+		}
+
+		dtb := ConnectToDB(t)
+		_, err := dtb.Exec("UPDATE orders SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1;", order.ID)
+		if err != nil {
+			t.Fatalf("Ошибка изменения срока оплаты заказа: %v", err)
+		}
+		if count, err := orders.NewDBRepo(dtb).ExpireOrders(); err != nil || count != 1 {
+			t.Fatalf("Ожидалась отмена одного заказа, но получено: %d, %v", count, err)
+		}
+
+		if expired := doOrder(t, http.MethodGet, ts.URL+"/orders/"+order.ID, buyerToken, nil, http.StatusOK); expired.Status != orders.StatusCancelled {
+			t.Errorf("Ожидался статус заказа %s, но получен %s", orders.StatusCancelled, expired.Status)
+		}
+		checkCardStatus(t, ts, cardID, sellerToken, cards.StatusPublished)
+
+		t.Run("оплата после отмены", func(t *testing.T) {
+			body, signature, err := prv.Complete(*order.PaymentID, payments.StatusSucceeded)
+			if err != nil {
+				t.Fatalf("Ошибка проведения платежа: %v", err)
+			}
+			sendWebhook(t, ts, body, signature, http.StatusNoContent)
+			sendWebhook(t, ts, body, signature, http.StatusNoContent)
+
+			late := doOrder(t, http.MethodGet, ts.URL+"/orders/"+order.ID, buyerToken, nil, http.StatusOK)
+			if late.Status != orders.StatusCancelled || !prv.IsRefunded(*order.PaymentID) ||
+				late.RefundStatus == nil || *late.RefundStatus != orders.RefundRefunded {
+				t.Errorf("Ожидался возврат платежа за отмененный заказ, но получено: %+v", late)
+			}
+		})
+	})
+}
+
+// TestOrderFromOffer тестирует создание заказа по принятому предложению цены и невозможность его повторного использования
+func TestOrderFromOffer(t *testing.T) {
+	ts, uhr, _ := setupTestServerForOrders(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+	buyerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user9", Password: "0rd3r_Buy3r!"}, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	cardID := postAndFindCard(t, ts, imageURL, sellerToken, "title42")
+	cardURL := ts.URL + "/cards/" + cardID
+
+	offer := doOffer(t, http.MethodPost, cardURL+"/offers", buyerToken, ohd.OfferRequest{Amount: 900}, http.StatusOK)
+	doOffer(t, http.MethodPost, ts.URL+"/offers/"+offer.ID+"/accept", sellerToken, nil, http.StatusOK)
+
+	order := doOrder(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{OfferID: offer.ID}, http.StatusOK)
+	if order.Status != orders.StatusPending || order.Amount != 900 || order.OfferID == nil || *order.OfferID != offer.ID {
+		t.Fatalf("Получен неожиданный заказ: %+v", order)
+	}
+	checkCardStatus(t, ts, cardID, sellerToken, cards.StatusReserved)
+	doOrder(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{OfferID: offer.ID}, http.StatusConflict)
+
+	doOrder(t, http.MethodPost, ts.URL+"/orders/"+order.ID+"/cancel", buyerToken, nil, http.StatusOK)
+	checkCardStatus(t, ts, cardID, sellerToken, cards.StatusPublished)
+	doOrder(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{OfferID: offer.ID}, http.StatusConflict)
+
+	t.Run("заказ по предложению после снятия объявления с продажи", func(t *testing.T) {
+		offer := doOffer(t, http.MethodPost, cardURL+"/offers", buyerToken, ohd.OfferRequest{Amount: 800}, http.StatusOK)
+		doOffer(t, http.MethodPost, ts.URL+"/offers/"+offer.ID+"/accept", sellerToken, nil, http.StatusOK)
+
+		for _, status := range []string{cards.StatusPublished, cards.StatusArchived} {
+			resp := DoJSON(t, http.MethodPatch, cardURL+"/status", sellerToken, uhd.ChangeStatusRequest{Status: status})
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+			}
+		}
+		doOrder(t, http.MethodPost, ts.URL+"/orders", buyerToken, ordhd.CreateOrderRequest{OfferID: offer.ID}, http.StatusConflict)
+	})
+}
+
+// postAndFindCard публикует объявление и получает его идентификатор
+func postAndFindCard(t *testing.T, ts *httptest.Server, imageURL, token, title string) string {
+	PostCards(t, ts, []uhd.PostACardRequest{
+		{Title: title, Text: "text of " + title, ImageURL: imageURL, Price: "1000", CategoryID: 3},
+	}, token)
+
+	feed := GetFeed(t, ts, "/get-cards", token)
+	if len(feed) == 0 || feed[0].Title != title {
+		t.Fatalf("в ленте объявлений не найдено только что созданное объявление %s", title)
+	}
+	return feed[0].ID
+}
+
+func doOrder(t *testing.T, method, fullURL, token string, body any, expectedCode int) orders.Order {
+	resp := DoJSON(t, method, fullURL, token, body)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedCode {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", expectedCode, resp.StatusCode)
+	}
+
+	var order orders.Order
+	if expectedCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+			t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+		}
+	}
+	return order
+}
+
+func sendWebhook(t *testing.T, ts *httptest.Server, body []byte, signature string, expectedCode int) {
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/payments/webhook", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set(payments.SignatureHeader, signature)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make a request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedCode {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", expectedCode, resp.StatusCode)
+	}
+}
+
+func checkCardStatus(t *testing.T, ts *httptest.Server, cardID, token, expected string) {
+	resp := DoJSON(t, http.MethodGet, ts.URL+"/cards/"+cardID, token, nil)
+	defer resp.Body.Close()
+
+	var card cards.CardOutput
+	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	if card.Status != expected {
+		t.Errorf("Ожидался статус объявления %s, но получен %s", expected, card.Status)
+	}
+}
@@ -0,0 +1,121 @@
+package user_test
+
+import (
+	"encoding/json"
+	rhd "marketplace/internal/handlers/reviews"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/reviews"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForReviews(t *testing.T) *httptest.Server {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	rhr := &rhd.ReviewsHandler{ReviewsRepo: reviews.NewDBRepo(dtb)}
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	rtr.HandleFunc("/users/{username}/reviews", rhr.GetReviews).Methods("GET")
+	rtr.HandleFunc("/users/{username}/reviews", middleware.RequireAuth(rhr.AddReview, ssn, true)).Methods("POST")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestReviews тестирует отзывы о продавце после завершенной сделки (заказ user9 у user1 из TestOrders)
+func TestReviews(t *testing.T) {
+	ts := setupTestServerForReviews(t)
+	sellerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}, "/sign-in")
+	buyerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user9", Password: "0rd3r_Buy3r!"}, "/sign-in")
+	otherToken := Authorize(t, ts, uhd.AuthRequest{Username: "user5", Password: "B^y3r_Pa55word"}, "/sign-in")
+	reviewsURL := ts.URL + "/users/user1/reviews"
+
+	t.Run("некорректная оценка", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, reviewsURL, buyerToken, rhd.AddReviewRequest{Rating: 6, Text: "Отлично"})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: оценка должна быть от 1 до 5")
+	})
+
+	t.Run("отзыв о себе", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, reviewsURL, sellerToken, rhd.AddReviewRequest{Rating: 5, Text: "Отлично"})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: нельзя оставить отзыв о себе")
+	})
+
+	t.Run("отзыв без сделки", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, reviewsURL, otherToken, rhd.AddReviewRequest{Rating: 1, Text: "Плохо"})
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusForbidden, resp.StatusCode)
+		}
+	})
+
+	resp := DoJSON(t, http.MethodPost, reviewsURL, buyerToken, rhd.AddReviewRequest{Rating: 4, Text: "Все пришло вовремя"})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+	var review reviews.Review
+	if err := json.NewDecoder(resp.Body).Decode(&review); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	if review.Reviewer != "user9" || review.Seller != "user1" || review.CardTitle != "title22" || review.Rating != 4 {
+		t.Errorf("Получен неожиданный отзыв: %+v", review)
+	}
+
+	t.Run("повторный отзыв по той же сделке", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, reviewsURL, buyerToken, rhd.AddReviewRequest{Rating: 5, Text: "Еще раз"})
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusForbidden, resp.StatusCode)
+		}
+	})
+
+	resp = DoJSON(t, http.MethodGet, reviewsURL, "", nil)
+	defer resp.Body.Close()
+	var sellerReviews reviews.SellerReviews
+	if err := json.NewDecoder(resp.Body).Decode(&sellerReviews); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	if sellerReviews.Count != 1 || sellerReviews.Rating == nil || *sellerReviews.Rating != 4 || len(sellerReviews.Items) != 1 {
+		t.Errorf("Получены неожиданные отзывы: %+v", sellerReviews)
+	}
+
+	feed := GetFeed(t, ts, "/get-cards?author=user1&per_page=1", "")
+	if len(feed) != 1 || feed[0].SellerRating == nil || *feed[0].SellerRating != 4 || feed[0].SellerReviewsCount != 1 {
+		t.Errorf("Ожидалась оценка автора объявления 4 по 1 отзыву, но получено: %+v", feed)
+	}
+
+	t.Run("удаление заказа и объявления с отзывом", func(t *testing.T) {
+		dtb := ConnectToDB(t)
+		if _, err := dtb.Exec("DELETE FROM orders WHERE id = $1;", review.OrderID); err == nil {
+			t.Fatal("Ожидалась ошибка удаления заказа, по которому оставлен отзыв")
+		}
+		_, err := dtb.Exec("DELETE FROM cards WHERE id = (SELECT card_id FROM orders WHERE id = $1);", review.OrderID)
+		if err == nil {
+			t.Fatal("Ожидалась ошибка удаления объявления, по заказу которого оставлен отзыв")
+		}
+	})
+
+	t.Run("отзывы несуществующего пользователя", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodGet, ts.URL+"/users/nobody/reviews", "", nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNotFound, resp.StatusCode)
+		}
+	})
+}
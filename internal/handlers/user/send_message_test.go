@@ -0,0 +1,158 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/conversations"
+	cvhd "marketplace/internal/handlers/conversations"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForMessages(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+	chr := &cvhd.ConversationsHandler{ConversationsRepo: conversations.NewDBRepo(dtb)}
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/sign-up", uhr.SignUp).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath+"/messages", middleware.RequireAuth(chr.StartConversation, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/me/conversations", middleware.RequireAuth(chr.GetConversations, ssn, true)).Methods("GET")
+	conversationPath := fmt.Sprintf("/conversations/{id:%s}/messages", ihd.UUIDRE)
+	rtr.HandleFunc(conversationPath, middleware.RequireAuth(chr.GetMessages, ssn, true)).Methods("GET")
+	rtr.HandleFunc(conversationPath, middleware.RequireAuth(chr.SendMessage, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// TestMessages тестирует сценарий переписки покупателя и продавца по объявлению
+func TestMessages(t *testing.T) {
+	ts, uhr := setupTestServerForMessages(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{{Title: "title17", Text: "text17", ImageURL: imageURL, Price: "17000", CategoryID: 3}}, sellerToken)
+
+	feed := GetFeed(t, ts, "/get-cards", sellerToken)
+	if len(feed) == 0 || feed[0].Title != "title17" {
+		t.Fatalf("в ленте объявлений не найдено только что созданное объявление")
+	}
+	cardURL := ts.URL + "/cards/" + feed[0].ID
+
+	buyerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user7", Password: "M3ss@ge_Buy3r"}, "/sign-up")
+	outsiderToken := Authorize(t, ts, uhd.AuthRequest{Username: "user5", Password: "B^y3r_Pa55word"}, "/sign-in")
+
+	t.Run("сообщение по своему объявлению", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, cardURL+"/messages", sellerToken, cvhd.SendMessageRequest{Text: "Привет"})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: нельзя написать сообщение по своему объявлению")
+	})
+
+	t.Run("пустое сообщение", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, cardURL+"/messages", buyerToken, cvhd.SendMessageRequest{})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: недостаточная длина сообщения -> сообщение должен содержать от 1 до 2000 символов")
+	})
+
+	first := sendMessage(t, cardURL+"/messages", buyerToken, "Здравствуйте, товар еще продается?")
+	second := sendMessage(t, cardURL+"/messages", buyerToken, "Могу забрать сегодня")
+	if first.ConversationID != second.ConversationID {
+		t.Fatalf("Ожидалось, что сообщения попадут в одну переписку")
+	}
+	messagesURL := ts.URL + "/conversations/" + first.ConversationID + "/messages"
+
+	cnvs := getConversations(t, ts, sellerToken)
+	if len(cnvs) == 0 || cnvs[0].ID != first.ConversationID {
+		t.Fatalf("в списке переписок продавца не найдена новая переписка")
+	}
+	if cnvs[0].Buyer != "user7" || cnvs[0].Seller != "user1" || cnvs[0].UnreadCount != 2 ||
+		cnvs[0].LastMessage == nil || cnvs[0].LastMessage.ID != second.ID {
+		t.Errorf("Получена неожиданная переписка: %+v", cnvs[0])
+	}
+
+	t.Run("чужая переписка", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodGet, messagesURL, outsiderToken, nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusForbidden, resp.StatusCode)
+		}
+	})
+
+	resp := DoJSON(t, http.MethodGet, messagesURL+"?per_page=1", sellerToken, nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+	var msgs []conversations.Message
+	if err := json.NewDecoder(resp.Body).Decode(&msgs); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].ID != second.ID {
+		t.Errorf("Ожидалось последнее сообщение переписки, но получено: %+v", msgs)
+	}
+
+	if cnvs := getConversations(t, ts, sellerToken); cnvs[0].UnreadCount != 0 {
+		t.Errorf("Ожидалось 0 непрочитанных сообщений, но получено %d", cnvs[0].UnreadCount)
+	}
+
+	reply := sendMessage(t, messagesURL, sellerToken, "Да, продается")
+	if reply.ConversationID != first.ConversationID || reply.Sender != "user1" {
+		t.Errorf("Получен неожиданный ответ продавца: %+v", reply)
+	}
+	if cnvs := getConversations(t, ts, buyerToken); len(cnvs) != 1 || cnvs[0].UnreadCount != 1 {
+		t.Errorf("Ожидалась 1 переписка покупателя с 1 непрочитанным сообщением, но получено: %+v", cnvs)
+	}
+}
+
+func sendMessage(t *testing.T, fullURL, token, text string) conversations.Message {
+	resp := DoJSON(t, http.MethodPost, fullURL, token, cvhd.SendMessageRequest{Text: text})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var msg conversations.Message
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return msg
+}
+
+func getConversations(t *testing.T, ts *httptest.Server, token string) []conversations.Conversation {
+	resp := DoJSON(t, http.MethodGet, ts.URL+"/me/conversations", token, nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var cnvs []conversations.Conversation
+	if err := json.NewDecoder(resp.Body).Decode(&cnvs); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return cnvs
+}
@@ -0,0 +1,85 @@
+package user
+
+import (
+	"encoding/json"
+	"log"
+	hdr "marketplace/internal/handlers"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// clientIP получает IP-адрес клиента из адреса соединения
+func clientIP(rqt *http.Request) string {
+	host, _, err := net.SplitHostPort(rqt.RemoteAddr)
+	if err != nil {
+		return rqt.RemoteAddr
+	}
+	return host
+}
+
+// GetSessions получает активные сессии текущего пользователя
+func (hnd *UserHandler) GetSessions(wrt http.ResponseWriter, rqt *http.Request) {
+	_, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	user, _ := hdr.UserFromContext(rqt.Context())
+	sessions, code, err := hnd.SessionsRepo.GetSessions(userID, user.SessionID)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "application/json")
+	wrt.WriteHeader(http.StatusOK)
+	errJSON := json.NewEncoder(wrt).Encode(sessions)
+	if errJSON != nil {
+		log.Printf("error while sending response body: %v\n", errJSON)
+	}
+}
+
+// RevokeSession завершает сессию текущего пользователя
+func (hnd *UserHandler) RevokeSession(wrt http.ResponseWriter, rqt *http.Request) {
+	sessionID := mux.Vars(rqt)["id"]
+
+	_, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	code, err := hnd.SessionsRepo.RevokeSession(sessionID, userID)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeAllSessions завершает все сессии текущего пользователя, включая текущую
+func (hnd *UserHandler) RevokeAllSessions(wrt http.ResponseWriter, rqt *http.Request) {
+	_, userID, ok := hnd.getCurrentUser(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	code, err := hnd.SessionsRepo.RevokeAllSessions(userID)
+	if code != hdr.OKCode {
+		errSend := hdr.SendByCode(wrt, code, err.Error())
+		if errSend != nil {
+			log.Printf("error while sending the error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.WriteHeader(http.StatusNoContent)
+}
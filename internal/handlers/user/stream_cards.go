@@ -0,0 +1,138 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"marketplace/internal/cards"
+	"marketplace/internal/handlers"
+	"net/http"
+	"time"
+)
+
+const (
+	// streamPollInterval — период проверки новых объявлений для потока
+	streamPollInterval = 2 * time.Second
+	// streamHeartbeatInterval — период отправки комментария, поддерживающего соединение
+	streamHeartbeatInterval = 15 * time.Second
+	// streamBatchSize — максимальное количество объявлений, получаемых за одну проверку
+	streamBatchSize int = 50
+	// streamRetryMs — рекомендуемая клиенту задержка переподключения в миллисекундах
+	streamRetryMs int = 3000
+)
+
+// StreamCards передает новые опубликованные объявления, удовлетворяющие фильтрам ленты
+// (price_min, price_max, category, tags, any_tags, q, author), по протоколу Server-Sent Events.
+// Объявления передаются в порядке публикации, включая опубликованные позже черновики.
+// Идентификатор события — курсор по транзакции публикации и идентификатору объявления, поэтому
+// клиент может продолжить поток с заголовком Last-Event-ID после переподключения
+func (hnd *UserHandler) StreamCards(wrt http.ResponseWriter, rqt *http.Request) {
+	params, ok := parseFeedParams(wrt, rqt)
+	if !ok {
+		return
+	}
+
+	username := optionalUsername(rqt)
+	params.Username = username
+
+	status := cards.StatusPublished
+	params.Status = &status
+	params.SortBy = cards.SortByPublication
+	params.Order = "asc"
+	params.PerPage = streamBatchSize
+	params.Offset = 0
+
+	if lastEventID := rqt.Header.Get("Last-Event-ID"); lastEventID != "" {
+		cursor, err := cards.DecodeCursor(lastEventID, params.SortBy)
+		if err != nil {
+			errSend := handlers.SendBadReq(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the bad request message: %v\n", errSend)
+			}
+			return
+		}
+		params.After = cursor
+	} else {
+		cursor, err := hnd.CardsRepo.StreamCursor()
+		if err != nil {
+			errSend := handlers.SendInternalServerError(wrt, err.Error())
+			if errSend != nil {
+				log.Printf("error while sending the internal server error message: %v\n", errSend)
+			}
+			return
+		}
+		params.After = cursor
+	}
+
+	flusher, ok := wrt.(http.Flusher)
+	if !ok {
+		errSend := handlers.SendInternalServerError(wrt, "потоковая передача не поддерживается")
+		if errSend != nil {
+			log.Printf("error while sending the internal server error message: %v\n", errSend)
+		}
+		return
+	}
+
+	wrt.Header().Set("Content-Type", "text/event-stream")
+	wrt.Header().Set("Cache-Control", "no-cache")
+	wrt.Header().Set("Connection", "keep-alive")
+	wrt.Header().Set("X-Accel-Buffering", "no")
+	wrt.WriteHeader(http.StatusOK)
+	fmt.Fprintf(wrt, "retry: %d\n\n", streamRetryMs)
+	flusher.Flush()
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		cardsList, err := hnd.CardsRepo.GetCards(params)
+		if err != nil {
+			log.Printf("error while getting new cards for the stream: %v\n", err)
+			return
+		}
+
+		for idx := range cardsList {
+			cursor := cards.NewCursor(&cardsList[idx], params.SortBy)
+			if err := writeCardEvent(wrt, cursor.Encode(), &cardsList[idx]); err != nil {
+				log.Printf("error while sending the card event: %v\n", err)
+				return
+			}
+			params.After = cursor
+		}
+		if len(cardsList) > 0 {
+			flusher.Flush()
+		}
+
+		// Если получено полное количество объявлений, следующие проверяются без ожидания
+		if len(cardsList) == streamBatchSize {
+			continue
+		}
+
+	wait:
+		for {
+			select {
+			case <-rqt.Context().Done():
+				return
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(wrt, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-poll.C:
+				break wait
+			}
+		}
+	}
+}
+
+// writeCardEvent отправляет объявление как событие card
+func writeCardEvent(wrt http.ResponseWriter, eventID string, card *cards.CardOutput) error {
+	data, err := json.Marshal(card)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(wrt, "id: %s\nevent: card\ndata: %s\n\n", eventID, data)
+	return err
+}
@@ -0,0 +1,165 @@
+package user_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"marketplace/internal/cards"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForStreamCards(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	rtr.HandleFunc("/cards/stream", middleware.RequireAuth(uhr.StreamCards, ssn, false)).Methods("GET")
+	cardPath := fmt.Sprintf("/cards/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(cardPath+"/status", middleware.RequireAuth(uhr.ChangeStatus, ssn, true)).Methods("PATCH")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	path := fmt.Sprintf("/images/{name:image%v\\.jpeg}", ihd.UUIDRE)
+	rtr.HandleFunc(path, ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// openStream подключается к потоку новых объявлений и дожидается начала передачи событий
+func openStream(t *testing.T, ctx context.Context, ts *httptest.Server, lastEventID string) (*http.Response, *bufio.Scanner) {
+	rqt, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/cards/stream?category=5&price_max=1000", nil)
+	if err != nil {
+		t.Fatalf("Ошибка создания запроса: %v", err)
+	}
+	if lastEventID != "" {
+		rqt.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(rqt)
+	if err != nil {
+		t.Fatalf("Ошибка выполнения запроса: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Ожидался Content-Type: text/event-stream, но получен: %s", contentType)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), "retry: ") {
+		t.Fatalf("Ожидалось начало потока с интервалом переподключения, но получено: %q", scanner.Text())
+	}
+	return resp, scanner
+}
+
+// readCardEvents читает count объявлений из потока и возвращает их заголовки и идентификаторы событий
+func readCardEvents(t *testing.T, scanner *bufio.Scanner, count int) ([]string, []string) {
+	var titles []string
+	var eventIDs []string
+	for len(titles) < count && scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			eventIDs = append(eventIDs, strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "data: "):
+			var card cards.CardOutput
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &card); err != nil {
+				t.Fatalf("Ошибка десериализации события: %v", err)
+			}
+			titles = append(titles, card.Title)
+		}
+	}
+	return titles, eventIDs
+}
+
+// TestStreamCards тестирует получение новых объявлений из потока, в том числе опубликованных черновиков,
+// и продолжение потока по Last-Event-ID
+func TestStreamCards(t *testing.T) {
+	ts, uhr := setupTestServerForStreamCards(t)
+	dtb := ConnectToDB(t)
+	auth := uhd.AuthRequest{Username: "user1", Password: "W#_?e9o!m+B>tk7j"}
+	sellerToken := Authorize(t, ts, auth, "/sign-in")
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+
+	// Черновик создан раньше остальных объявлений, но опубликован после них
+	PostCards(t, ts, []uhd.PostACardRequest{
+		{Title: "title30", Text: "text30", ImageURL: imageURL, Price: "300", CategoryID: 5, Status: cards.StatusDraft},
+	}, sellerToken)
+	var draftID string
+	if err := dtb.QueryRow("SELECT id FROM cards WHERE title = 'title30';").Scan(&draftID); err != nil {
+		t.Fatalf("Ошибка получения черновика: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, scanner := openStream(t, ctx, ts, "")
+
+	PostCards(t, ts, []uhd.PostACardRequest{
+		{Title: "title18", Text: "text18", ImageURL: imageURL, Price: "180", CategoryID: 5},
+		{Title: "title19", Text: "text19", ImageURL: imageURL, Price: "190", CategoryID: 6},
+		{Title: "title20", Text: "text20", ImageURL: imageURL, Price: "200000", CategoryID: 6},
+	}, sellerToken)
+	resp := DoJSON(t, http.MethodPatch, ts.URL+"/cards/"+draftID+"/status", sellerToken,
+		uhd.ChangeStatusRequest{Status: cards.StatusPublished})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Категория 6 (Мебель) — подкатегория категории 5 (Дом и сад), title20 не подходит по цене
+	titles, eventIDs := readCardEvents(t, scanner, 3)
+	if strings.Join(titles, ",") != "title18,title19,title30" {
+		t.Fatalf("Ожидались объявления title18,title19,title30, но получены: %v", titles)
+	}
+	if len(eventIDs) != 3 || eventIDs[0] == eventIDs[1] || eventIDs[1] == eventIDs[2] {
+		t.Errorf("Ожидались разные идентификаторы событий, но получены: %v", eventIDs)
+	}
+
+	t.Run("продолжение потока по Last-Event-ID", func(t *testing.T) {
+		if len(eventIDs) == 0 {
+			t.Skip("нет идентификатора события")
+		}
+		_, scanner := openStream(t, ctx, ts, eventIDs[0])
+
+		titles, _ := readCardEvents(t, scanner, 2)
+		if strings.Join(titles, ",") != "title19,title30" {
+			t.Errorf("Ожидались объявления title19,title30, но получены: %v", titles)
+		}
+	})
+
+	t.Run("некорректный Last-Event-ID", func(t *testing.T) {
+		rqt, err := http.NewRequest(http.MethodGet, ts.URL+"/cards/stream", nil)
+		if err != nil {
+			t.Fatalf("Ошибка создания запроса: %v", err)
+		}
+		rqt.Header.Set("Last-Event-ID", "invalid")
+
+		resp, err := http.DefaultClient.Do(rqt)
+		if err != nil {
+			t.Fatalf("Ошибка выполнения запроса: %v", err)
+		}
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: некорректный курсор")
+	})
+}
@@ -2,10 +2,24 @@ package user
 
 import (
 	"marketplace/internal/cards"
+	"marketplace/internal/categories"
+	"marketplace/internal/handlers"
+	"marketplace/internal/searches"
+	"marketplace/internal/sessions"
 	"marketplace/internal/user"
+	"net/http"
 )
 
 type UserHandler struct {
-	UserRepo  user.UserRepo
-	CardsRepo cards.CardsRepo
+	UserRepo       user.UserRepo
+	CardsRepo      cards.CardsRepo
+	CategoriesRepo categories.CategoriesRepo
+	SearchesRepo   searches.SearchesRepo
+	SessionsRepo   sessions.SessionsRepo
+}
+
+// getCurrentUser получает логин и идентификатор авторизованного пользователя.
+// В случае ошибки отправляет сообщение об ошибке и возвращает false
+func (hnd *UserHandler) getCurrentUser(wrt http.ResponseWriter, rqt *http.Request) (string, string, bool) {
+	return handlers.GetCurrentUser(wrt, rqt)
 }
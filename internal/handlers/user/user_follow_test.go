@@ -0,0 +1,136 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"marketplace/internal/user"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForFollows(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/sign-up", uhr.SignUp).Methods("POST")
+	rtr.HandleFunc("/post-a-card", middleware.RequireAuth(uhr.PostACard, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/users/{username}/follow", middleware.RequireAuth(uhr.Follow, ssn, true)).Methods("POST")
+	rtr.HandleFunc("/users/{username}/follow", middleware.RequireAuth(uhr.Unfollow, ssn, true)).Methods("DELETE")
+	rtr.HandleFunc("/users/{username}/followers", uhr.GetFollowers).Methods("GET")
+	rtr.HandleFunc("/users/{username}/following", uhr.GetFollowing).Methods("GET")
+	rtr.HandleFunc("/me/feed", middleware.RequireAuth(uhr.GetFollowingFeed, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	rtr.HandleFunc(fmt.Sprintf("/images/{name:image%s\\.jpeg}", ihd.UUIDRE), ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// getFollows получает список подписчиков или подписок пользователя
+func getFollows(t *testing.T, ts *httptest.Server, path string) []user.Follow {
+	resp := DoJSON(t, http.MethodGet, ts.URL+path, "", nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var follows []user.Follow
+	if err := json.NewDecoder(resp.Body).Decode(&follows); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return follows
+}
+
+// TestFollows тестирует подписку на продавца и ленту объявлений продавцов из подписок
+func TestFollows(t *testing.T) {
+	ts, uhr := setupTestServerForFollows(t)
+	auth := uhd.AuthRequest{Username: "user10", Password: "F0ll0w_S3ller!"}
+	sellerToken := Authorize(t, ts, auth, "/sign-up")
+	followerToken := Authorize(t, ts, uhd.AuthRequest{Username: "user5", Password: "B^y3r_Pa55word"}, "/sign-in")
+
+	imageURL := getImageURL(t, ts, uhr, auth.Username)
+	PostCards(t, ts, []uhd.PostACardRequest{
+		{Title: "title24", Text: "text24", ImageURL: imageURL, Price: "24000", CategoryID: 3},
+		{Title: "title25", Text: "text25", ImageURL: imageURL, Price: "25000", CategoryID: 3},
+	}, sellerToken)
+
+	if feed := GetFeed(t, ts, "/me/feed", followerToken); len(feed) != 0 {
+		t.Errorf("Ожидалась пустая лента без подписок, но получено объявлений: %d", len(feed))
+	}
+
+	t.Run("подписка на себя", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, ts.URL+"/users/user10/follow", sellerToken, nil)
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: нельзя подписаться на себя")
+	})
+
+	t.Run("подписка на несуществующего пользователя", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodPost, ts.URL+"/users/nobody/follow", followerToken, nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNotFound, resp.StatusCode)
+		}
+	})
+
+	for range 2 {
+		resp := DoJSON(t, http.MethodPost, ts.URL+"/users/user10/follow", followerToken, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+		}
+	}
+
+	followers := getFollows(t, ts, "/users/user10/followers")
+	if len(followers) != 1 || followers[0].Username != "user5" {
+		t.Errorf("Ожидался один подписчик user5, но получено: %+v", followers)
+	}
+	following := getFollows(t, ts, "/users/user5/following")
+	if len(following) != 1 || following[0].Username != "user10" {
+		t.Errorf("Ожидалась одна подписка на user10, но получено: %+v", following)
+	}
+
+	feed := GetFeed(t, ts, "/me/feed?sort_by=price&order=asc", followerToken)
+	if len(feed) != 2 || feed[0].Title != "title24" || feed[1].Title != "title25" {
+		t.Errorf("Ожидались объявления title24 и title25 по возрастанию цены, но получено: %+v", feed)
+	}
+	feed = GetFeed(t, ts, "/me/feed?price_min=24500", followerToken)
+	if len(feed) != 1 || feed[0].Title != "title25" {
+		t.Errorf("Ожидалось объявление title25 с ценой от 24500, но получено: %+v", feed)
+	}
+
+	resp := DoJSON(t, http.MethodDelete, ts.URL+"/users/user10/follow", followerToken, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+	}
+	if feed := GetFeed(t, ts, "/me/feed", followerToken); len(feed) != 0 {
+		t.Errorf("Ожидалась пустая лента после отмены подписки, но получено объявлений: %d", len(feed))
+	}
+	if followers := getFollows(t, ts, "/users/user10/followers"); len(followers) != 0 {
+		t.Errorf("Ожидался пустой список подписчиков, но получено: %+v", followers)
+	}
+
+	t.Run("лента без авторизации", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodGet, ts.URL+"/me/feed", "", nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package user_test
+
+import (
+	"encoding/json"
+	"fmt"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"marketplace/internal/user"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForProfile(t *testing.T) (*httptest.Server, *uhd.UserHandler) {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+	ihr := GetImagesHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/get-cards", middleware.RequireAuth(uhr.GetCards, ssn, false)).Methods("GET")
+	rtr.HandleFunc("/users/{username}", uhr.GetProfile).Methods("GET")
+	rtr.HandleFunc("/me/profile", middleware.RequireAuth(uhr.UpdateProfile, ssn, true)).Methods("PATCH")
+	rtr.HandleFunc("/images/create", ihr.CreateImage).Methods("GET")
+	rtr.HandleFunc("/images", ihr.LoadImage).Methods("POST")
+	rtr.HandleFunc(fmt.Sprintf("/images/{name:image%s\\.jpeg}", ihd.UUIDRE), ihr.GetImage).Methods("GET")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts, uhr
+}
+
+// getProfile получает публичный профиль пользователя
+func getProfile(t *testing.T, ts *httptest.Server, username string) user.Profile {
+	resp := DoJSON(t, http.MethodGet, ts.URL+"/users/"+username, "", nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var profile user.Profile
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return profile
+}
+
+// getImageName загружает изображение от имени пользователя и возвращает его имя
+func getImageName(t *testing.T, ts *httptest.Server, uhr *uhd.UserHandler, username string) string {
+	imageURL := getImageURL(t, ts, uhr, username)
+	return strings.TrimSuffix(strings.TrimPrefix(imageURL, ts.URL+"/images/"), ".jpeg")
+}
+
+// TestProfile тестирует публичный профиль пользователя и его изменение
+func TestProfile(t *testing.T) {
+	ts, uhr := setupTestServerForProfile(t)
+	token := Authorize(t, ts, uhd.AuthRequest{Username: "user3", Password: "Q#_~s1o!m+B&t/9j0g{"}, "/sign-in")
+	profileURL := ts.URL + "/me/profile"
+
+	profile := getProfile(t, ts, "user3")
+	if profile.DisplayName != "user3" || profile.AvatarURL != nil || profile.Bio != "" || profile.RegisteredAt.IsZero() {
+		t.Errorf("Получен неожиданный профиль по умолчанию: %+v", profile)
+	}
+
+	t.Run("слишком длинное отображаемое имя", func(t *testing.T) {
+		displayName := strings.Repeat("и", 51)
+		resp := DoJSON(t, http.MethodPatch, profileURL, token, uhd.UpdateProfileRequest{DisplayName: &displayName})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: превышена допустимая длина отображаемого имени -> отображаемое имя должен содержать от 1 до 50 символов")
+	})
+
+	t.Run("неправильное имя изображения", func(t *testing.T) {
+		avatar := "avatar.png"
+		resp := DoJSON(t, http.MethodPatch, profileURL, token, uhd.UpdateProfileRequest{AvatarImage: &avatar})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "неправильное имя изображения")
+	})
+
+	t.Run("изображение другого пользователя", func(t *testing.T) {
+		avatar := getImageName(t, ts, uhr, "user4")
+		resp := DoJSON(t, http.MethodPatch, profileURL, token, uhd.UpdateProfileRequest{AvatarImage: &avatar})
+		defer resp.Body.Close()
+
+		HandleBadReq(t, resp, "ошибка: изображение аватара не существует или загружено другим пользователем")
+	})
+
+	displayName, bio, avatar := "Иван", "Продаю ненужное", getImageName(t, ts, uhr, "user3")
+	resp := DoJSON(t, http.MethodPatch, profileURL, token, uhd.UpdateProfileRequest{DisplayName: &displayName, Bio: &bio, AvatarImage: &avatar})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	profile = getProfile(t, ts, "user3")
+	if profile.DisplayName != displayName || profile.Bio != bio || profile.AvatarURL == nil {
+		t.Fatalf("Получен неожиданный профиль после изменения: %+v", profile)
+	}
+	avatarResp := DoJSON(t, http.MethodGet, ts.URL+*profile.AvatarURL, "", nil)
+	defer avatarResp.Body.Close()
+	if avatarResp.StatusCode != http.StatusOK {
+		t.Errorf("Ожидался код состояния ответа для аватара: %d, но получен: %d", http.StatusOK, avatarResp.StatusCode)
+	}
+
+	t.Run("сброс отображаемого имени и аватара", func(t *testing.T) {
+		empty := ""
+		resp := DoJSON(t, http.MethodPatch, profileURL, token, uhd.UpdateProfileRequest{DisplayName: &empty, AvatarImage: &empty})
+		defer resp.Body.Close()
+
+		profile := getProfile(t, ts, "user3")
+		if profile.DisplayName != "user3" || profile.AvatarURL != nil || profile.Bio != bio {
+			t.Errorf("Получен неожиданный профиль после сброса: %+v", profile)
+		}
+	})
+
+	t.Run("активные объявления и оценка продавца", func(t *testing.T) {
+		profile := getProfile(t, ts, "user1")
+		feed := GetFeed(t, ts, "/get-cards?author=user1&per_page=1000", "")
+		if profile.ActiveCardsCount != len(feed) {
+			t.Errorf("Ожидалось активных объявлений: %d, но получено: %d", len(feed), profile.ActiveCardsCount)
+		}
+		if profile.Rating == nil || *profile.Rating != 4 || profile.ReviewsCount != 1 {
+			t.Errorf("Ожидалась оценка продавца 4 по 1 отзыву, но получено: %+v", profile)
+		}
+	})
+
+	t.Run("несуществующий пользователь", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodGet, ts.URL+"/users/nobody", "", nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNotFound, resp.StatusCode)
+		}
+	})
+}
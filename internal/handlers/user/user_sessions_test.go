@@ -0,0 +1,183 @@
+package user_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	ihd "marketplace/internal/handlers/images"
+	uhd "marketplace/internal/handlers/user"
+	"marketplace/internal/middleware"
+	"marketplace/internal/sessions"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func setupTestServerForSessions(t *testing.T) *httptest.Server {
+	dtb := ConnectToDB(t)
+	ssn := sessions.NewDBRepo(dtb)
+	uhr := GetUserHandler(t)
+
+	rtr := mux.NewRouter()
+	rtr.HandleFunc("/sign-in", uhr.SignIn).Methods("POST")
+	rtr.HandleFunc("/token/refresh", uhr.RefreshToken).Methods("POST")
+	rtr.HandleFunc("/me/sessions", middleware.RequireAuth(uhr.GetSessions, ssn, true)).Methods("GET")
+	rtr.HandleFunc("/me/sessions", middleware.RequireAuth(uhr.RevokeAllSessions, ssn, true)).Methods("DELETE")
+	sessionPath := fmt.Sprintf("/me/sessions/{id:%s}", ihd.UUIDRE)
+	rtr.HandleFunc(sessionPath, middleware.RequireAuth(uhr.RevokeSession, ssn, true)).Methods("DELETE")
+
+	ts := httptest.NewServer(rtr)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// signInFrom авторизует пользователя с устройства userAgent и возвращает access-токен и refresh-токен
+func signInFrom(t *testing.T, ts *httptest.Server, auth uhd.AuthRequest, userAgent string) (string, string) {
+	data, err := json.Marshal(auth)
+	if err != nil {
+		t.Fatalf("Ошибка сериализации тела запроса клиента: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/sign-in", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make a request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+	return resp.Header.Get("Authorization"), resp.Header.Get(uhd.RefreshTokenHeader)
+}
+
+// getSessions получает активные сессии пользователя
+func getSessions(t *testing.T, ts *httptest.Server, token string) []sessions.Session {
+	resp := DoJSON(t, http.MethodGet, ts.URL+"/me/sessions", token, nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result []sessions.Session
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return result
+}
+
+// findSession ищет сессию, созданную с устройства userAgent
+func findSession(list []sessions.Session, userAgent string) *sessions.Session {
+	for i := range list {
+		if list[i].UserAgent == userAgent {
+			return &list[i]
+		}
+	}
+	return nil
+}
+
+// TestSessions тестирует список активных сессий и их завершение
+func TestSessions(t *testing.T) {
+	ts := setupTestServerForSessions(t)
+	auth := uhd.AuthRequest{Username: "user4", Password: "P@s5_w0rd!Ab"}
+	laptopToken, _ := signInFrom(t, ts, auth, "laptop-browser")
+	phoneToken, phoneRefresh := signInFrom(t, ts, auth, "phone-app")
+
+	list := getSessions(t, ts, laptopToken)
+	laptop, phone := findSession(list, "laptop-browser"), findSession(list, "phone-app")
+	if laptop == nil || phone == nil {
+		t.Fatalf("Ожидались сессии с ноутбука и телефона, но получено: %+v", list)
+	}
+	if !laptop.IsCurrent || phone.IsCurrent || laptop.IP == "" || laptop.CreatedAt.IsZero() || laptop.LastUsedAt.IsZero() {
+		t.Errorf("Получены неожиданные сессии: %+v, %+v", laptop, phone)
+	}
+
+	t.Run("обновление даты последнего использования", func(t *testing.T) {
+		dtb := ConnectToDB(t)
+		lastUsedAt := func() time.Time {
+			var lastUsed time.Time
+			if err := dtb.QueryRow("SELECT last_used_at FROM sessions WHERE id = $1;", laptop.ID).Scan(&lastUsed); err != nil {
+				t.Fatalf("Ошибка получения сессии: %v", err)
+			}
+			return lastUsed
+		}
+
+		before := lastUsedAt()
+		getSessions(t, ts, laptopToken)
+		if after := lastUsedAt(); !after.Equal(before) {
+			t.Errorf("Дата последнего использования не должна обновляться чаще раза в минуту: %v, %v", before, after)
+		}
+
+		if _, err := dtb.Exec("UPDATE sessions SET last_used_at = last_used_at - INTERVAL '2 minutes' WHERE id = $1;", laptop.ID); err != nil {
+			t.Fatalf("Ошибка изменения сессии: %v", err)
+		}
+		getSessions(t, ts, laptopToken)
+		if after := lastUsedAt(); !after.After(before) {
+			t.Errorf("Ожидалось обновление даты последнего использования, но получено: %v, %v", before, after)
+		}
+	})
+
+	t.Run("недействительный токен", func(t *testing.T) {
+		for _, inToken := range []string{"invalid", "Bearer " + laptopToken + "x"} {
+			resp := DoJSON(t, http.MethodGet, ts.URL+"/me/sessions", inToken, nil)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("завершение сессии другого пользователя", func(t *testing.T) {
+		otherToken, _ := signInFrom(t, ts, uhd.AuthRequest{Username: "user3", Password: "Q#_~s1o!m+B&t/9j0g{"}, "other")
+		resp := DoJSON(t, http.MethodDelete, ts.URL+"/me/sessions/"+phone.ID, otherToken, nil)
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNotFound, resp.StatusCode)
+		}
+	})
+
+	resp := DoJSON(t, http.MethodDelete, ts.URL+"/me/sessions/"+phone.ID, laptopToken, nil)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	t.Run("токены завершенной сессии", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodGet, ts.URL+"/me/sessions", phoneToken, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, resp.StatusCode)
+		}
+
+		if _, code := refreshTokens(t, ts, phoneRefresh); code != http.StatusUnauthorized {
+			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, code)
+		}
+	})
+
+	if findSession(getSessions(t, ts, laptopToken), "phone-app") != nil {
+		t.Error("Завершенная сессия не должна быть в списке активных сессий")
+	}
+
+	t.Run("выход на всех устройствах", func(t *testing.T) {
+		resp := DoJSON(t, http.MethodDelete, ts.URL+"/me/sessions", laptopToken, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusNoContent, resp.StatusCode)
+		}
+
+		resp = DoJSON(t, http.MethodGet, ts.URL+"/me/sessions", laptopToken, nil)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusUnauthorized, resp.StatusCode)
+		}
+	})
+}
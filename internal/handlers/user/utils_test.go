@@ -7,17 +7,25 @@ import (
 	"fmt"
 	"io"
 	"marketplace/internal/cards"
+	"marketplace/internal/categories"
 	"marketplace/internal/datastore"
 	"marketplace/internal/handlers"
 	ihd "marketplace/internal/handlers/images"
 	uhd "marketplace/internal/handlers/user"
 	"marketplace/internal/images"
+	"marketplace/internal/searches"
+	"marketplace/internal/sessions"
+	"marketplace/internal/token"
 	"marketplace/internal/user"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 )
 
+// keySetOnce обеспечивает однократную установку ключей подписи токенов для тестов
+var keySetOnce sync.Once
+
 func ConnectToDB(t *testing.T) *sql.DB {
 	dtb, err := datastore.CreateNewDB()
 	if err != nil {
@@ -27,12 +35,23 @@ func ConnectToDB(t *testing.T) *sql.DB {
 }
 
 func GetUserHandler(t *testing.T) *uhd.UserHandler {
+	keySetOnce.Do(func() {
+		ks, err := token.GenerateKeySet()
+		if err != nil {
+			t.Fatalf("Ошибка создания набора ключей: %v", err)
+		}
+		token.SetKeySet(ks)
+	})
+
 	dtb := ConnectToDB(t)
 	usr := user.NewDBRepo(dtb)
 	cards := cards.NewDBRepo(dtb)
 	userHandler := &uhd.UserHandler{
-		UserRepo:  usr,
-		CardsRepo: cards,
+		UserRepo:       usr,
+		CardsRepo:      cards,
+		CategoriesRepo: categories.NewDBRepo(dtb),
+		SearchesRepo:   searches.NewDBRepo(dtb),
+		SessionsRepo:   sessions.NewDBRepo(dtb),
 	}
 	return userHandler
 }
@@ -151,3 +170,59 @@ func getImageURL(t *testing.T, ts *httptest.Server, uhr *uhd.UserHandler, userna
 	imageURL := fmt.Sprintf("%s/images/%s.jpeg", ts.URL, loadResp.ImageName)
 	return imageURL
 }
+
+func GetFeed(t *testing.T, ts *httptest.Server, path, token string) []cards.CardOutput {
+	req, err := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make a request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Ожидался код состояния ответа: %d, но получен: %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var feed []cards.CardOutput
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		t.Fatalf("Ошибка десериализации ответа сервера: %v", err)
+	}
+	return feed
+}
+
+func DoJSON(t *testing.T, method, fullURL, token string, body any) *http.Response {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Ошибка сериализации тела запроса клиента: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, fullURL, reader)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make a request: %v", err)
+	}
+	return resp
+}
@@ -2,35 +2,34 @@ package middleware
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"log"
+	hdr "marketplace/internal/handlers"
 	"marketplace/internal/token"
 	"net/http"
 )
 
-type contextKey string
-
-const (
-	KeyIsAuthenticated = contextKey("isAuthenticated")
-)
-
-func RequireAuth(next http.HandlerFunc, dtb *sql.DB, isRequired bool) http.HandlerFunc {
+// RequireAuth проверяет токен запроса и сессию, в рамках которой он выдан, и сохраняет пользователя
+// в контексте запроса. Если авторизация не обязательна, запрос без токена передается дальше без пользователя
+func RequireAuth(next http.HandlerFunc, checker token.SessionChecker, isRequired bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		check, err := token.Check(r, dtb)
-		if err != nil && (isRequired || err != token.ErrNoToken) {
-			log.Printf("internal error during token check: %v\n", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		claims, err := token.Check(r, checker)
+		switch {
+		case errors.Is(err, token.ErrNoToken) && !isRequired:
+			next.ServeHTTP(w, r)
 			return
-		}
-
-		if isRequired && !check {
-			log.Println("the token check has failed")
+		case token.IsUnauthorized(err):
+			log.Printf("the token check has failed: %v\n", err)
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
+		case err != nil:
+			log.Printf("internal error during token check: %v\n", err)
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
 		}
 
-		ctx := context.WithValue(r.Context(), KeyIsAuthenticated, check)
-		r = r.WithContext(ctx)
-		next.ServeHTTP(w, r)
+		user := &hdr.AuthUser{ID: claims.Subject, Username: claims.Username, SessionID: claims.SessionID}
+		ctx := context.WithValue(r.Context(), hdr.KeyUser, user)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
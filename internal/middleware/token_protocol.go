@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TokenProtocol — подпротокол WebSocket, за которым в заголовке Sec-WebSocket-Protocol следует access-токен
+const TokenProtocol string = "access_token"
+
+// TokenFromProtocol переносит токен из заголовка Sec-WebSocket-Protocol ("access_token, <токен>")
+// в заголовок Authorization, если он не задан. Используется для подключений WebSocket, при которых
+// браузер не позволяет задать заголовки запроса, а токен не должен попадать в адрес запроса и журналы
+func TokenFromProtocol(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			if accessToken := protocolToken(r); accessToken != "" {
+				r.Header.Set("Authorization", accessToken)
+			}
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// protocolToken получает токен, следующий за подпротоколом TokenProtocol
+func protocolToken(r *http.Request) string {
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			protocols = append(protocols, strings.TrimSpace(protocol))
+		}
+	}
+
+	for idx := 0; idx+1 < len(protocols); idx++ {
+		if protocols[idx] == TokenProtocol {
+			return protocols[idx+1]
+		}
+	}
+	return ""
+}
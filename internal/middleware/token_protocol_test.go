@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestTokenFromProtocol тестирует перенос токена из заголовка Sec-WebSocket-Protocol в заголовок Authorization
+func TestTokenFromProtocol(t *testing.T) {
+	tests := map[string]struct {
+		authorization string
+		protocols     []string
+		want          string
+	}{
+		"токен в подпротоколе":          {"", []string{"access_token, token"}, "token"},
+		"токен в отдельном заголовке":   {"", []string{"chat", "access_token", "token"}, "token"},
+		"заголовок Authorization задан": {"Bearer header", []string{"access_token, token"}, "Bearer header"},
+		"подпротокол без токена":        {"", []string{"access_token"}, ""},
+		"без подпротокола":              {"", nil, ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rqt := httptest.NewRequest(http.MethodGet, "/ws/chat", nil)
+			if tt.authorization != "" {
+				rqt.Header.Set("Authorization", tt.authorization)
+			}
+			for _, protocol := range tt.protocols {
+				rqt.Header.Add("Sec-WebSocket-Protocol", protocol)
+			}
+
+			var got string
+			TokenFromProtocol(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+			})(httptest.NewRecorder(), rqt)
+
+			if got != tt.want {
+				t.Errorf("Ожидался заголовок Authorization %q, но получен %q", tt.want, got)
+			}
+		})
+	}
+}
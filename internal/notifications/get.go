@@ -0,0 +1,44 @@
+package notifications
+
+import "fmt"
+
+// GetNotifications получает уведомления пользователя, начиная с самых новых
+func (repo *NotificationsDBRepository) GetNotifications(userID string, unreadOnly bool, limit, offset int) ([]Notification, error) {
+	query := `
+        SELECT n.id, n.kind, n.card_id, c.title, n.search_id, s.name, n.is_read, n.created_at
+        FROM notifications n
+        LEFT JOIN cards c ON c.id = n.card_id
+        LEFT JOIN saved_searches s ON s.id = n.search_id
+        WHERE n.user_id = $1 AND (NOT $2 OR NOT n.is_read)
+        ORDER BY n.created_at DESC, n.id DESC
+        LIMIT $3 OFFSET $4;
+    `
+
+	rows, err := repo.dtb.Query(query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение уведомлений: %v", err)
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var ntf Notification
+		if err := rows.Scan(&ntf.ID, &ntf.Kind, &ntf.CardID, &ntf.CardTitle, &ntf.SearchID, &ntf.SearchName, &ntf.IsRead, &ntf.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, ntf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkAllRead отмечает все уведомления пользователя прочитанными
+func (repo *NotificationsDBRepository) MarkAllRead(userID string) error {
+	_, err := repo.dtb.Exec("UPDATE notifications SET is_read = TRUE WHERE user_id = $1 AND NOT is_read;", userID)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к базе данных: отметка уведомлений прочитанными: %v", err)
+	}
+	return nil
+}
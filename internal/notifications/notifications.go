@@ -0,0 +1,35 @@
+package notifications
+
+import "time"
+
+// Виды уведомлений
+const (
+	// KindSearchMatch — новое объявление удовлетворяет сохраненному поиску
+	KindSearchMatch string = "search_match"
+)
+
+type Notification struct {
+	// ID — идентификатор уведомления
+	ID string `json:"id"`
+	// Kind — вид уведомления
+	Kind string `json:"kind"`
+	// CardID — объявление, к которому относится уведомление
+	CardID *string `json:"card_id,omitempty"`
+	// CardTitle — заголовок объявления
+	CardTitle *string `json:"card_title,omitempty"`
+	// SearchID — сохраненный поиск, к которому относится уведомление
+	SearchID *string `json:"search_id,omitempty"`
+	// SearchName — название сохраненного поиска
+	SearchName *string `json:"search_name,omitempty"`
+	// IsRead — признак прочтения
+	IsRead bool `json:"is_read"`
+	// CreatedAt — дата создания
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type NotificationsRepo interface {
+	// GetNotifications получает уведомления пользователя, начиная с самых новых
+	GetNotifications(userID string, unreadOnly bool, limit, offset int) ([]Notification, error)
+	// MarkAllRead отмечает все уведомления пользователя прочитанными
+	MarkAllRead(userID string) error
+}
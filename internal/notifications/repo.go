@@ -0,0 +1,13 @@
+package notifications
+
+import (
+	"database/sql"
+)
+
+type NotificationsDBRepository struct {
+	dtb *sql.DB
+}
+
+func NewDBRepo(sdb *sql.DB) *NotificationsDBRepository {
+	return &NotificationsDBRepository{dtb: sdb}
+}
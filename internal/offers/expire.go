@@ -0,0 +1,66 @@
+package offers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"marketplace/internal/cards"
+	"time"
+)
+
+// ExpirySweepInterval — интервал между проверками истекших предложений
+const ExpirySweepInterval = 10 * time.Minute
+
+// ExpireOffers отмечает истекшими ожидающие ответа предложения с истекшим сроком действия
+// и принятые предложения, по которым в срок не создан заказ. Зарезервированные по ним объявления
+// без незавершенных заказов снова публикуются
+func (repo *OffersDBRepository) ExpireOffers() (int64, error) {
+	query := `
+        WITH lapsed AS (
+            UPDATE offers ofr SET status = $1
+            WHERE ofr.status = $3 AND ofr.expires_at <= NOW()
+                AND NOT EXISTS (SELECT 1 FROM orders ord WHERE ord.offer_id = ofr.id)
+            RETURNING card_id
+        ), released AS (
+            UPDATE cards c SET status = $4
+            WHERE c.status = $5 AND c.id IN (SELECT card_id FROM lapsed)
+                AND NOT EXISTS (
+                    SELECT 1 FROM orders ord
+                    WHERE ord.card_id = c.id AND ord.status IN ('pending', 'paid', 'shipped')
+                )
+            RETURNING c.id
+        ), expired AS (
+            UPDATE offers SET status = $1
+            WHERE status = $2 AND expires_at <= NOW()
+            RETURNING id
+        )
+        SELECT (SELECT COUNT(*) FROM lapsed) + (SELECT COUNT(*) FROM expired);
+    `
+	var count int64
+	err := repo.dtb.QueryRow(query, StatusExpired, StatusPending, StatusAccepted, cards.StatusPublished, cards.StatusReserved).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса к базе данных: отметка истекших предложений: %v", err)
+	}
+	return count, nil
+}
+
+// RunExpirySweeper периодически отмечает истекшие предложения до отмены контекста
+func (repo *OffersDBRepository) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := repo.ExpireOffers()
+		if err != nil {
+			log.Printf("error while expiring offers: %v\n", err)
+		} else if count > 0 {
+			log.Printf("%d offers have expired\n", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
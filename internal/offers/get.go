@@ -0,0 +1,108 @@
+package offers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// selectOffers — запрос предложений с данными объявления и участников. Предложение,
+// срок действия которого истек, считается истекшим еще до того, как его обработает сборщик
+const selectOffers string = `
+    SELECT
+        o.id,
+        o.card_id,
+        c.title,
+        c.price,
+        b.username,
+        s.username,
+        p.username,
+        o.parent_id,
+        o.amount,
+        CASE WHEN o.status = 'pending' AND o.expires_at <= NOW() THEN 'expired' ELSE o.status END,
+        o.created_at,
+        o.expires_at
+    FROM offers o
+    JOIN cards c ON c.id = o.card_id
+    JOIN users b ON b.id = o.buyer_id
+    JOIN users s ON s.id = o.seller_id
+    JOIN users p ON p.id = o.proposed_by
+`
+
+// rowScanner — строка или строки результата запроса
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanOffer считывает предложение из результата запроса selectOffers
+func scanOffer(row rowScanner) (*Offer, error) {
+	var offer Offer
+	err := row.Scan(&offer.ID, &offer.CardID, &offer.CardTitle, &offer.CardPrice, &offer.Buyer, &offer.Seller,
+		&offer.ProposedBy, &offer.ParentID, &offer.Amount, &offer.Status, &offer.CreatedAt, &offer.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// getOffer получает предложение по его идентификатору
+func (repo *OffersDBRepository) getOffer(offerID string) (*Offer, int, error) {
+	offer, err := scanOffer(repo.dtb.QueryRow(selectOffers+" WHERE o.id = $1;", offerID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("предложение не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение предложения: %v", err)
+	}
+	return offer, hdr.OKCode, nil
+}
+
+// queryOffers получает список предложений
+func (repo *OffersDBRepository) queryOffers(where string, args ...any) ([]Offer, error) {
+	rows, err := repo.dtb.Query(selectOffers+" WHERE "+where+" ORDER BY o.created_at DESC, o.id DESC;", args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение предложений: %v", err)
+	}
+	defer rows.Close()
+
+	offers := []Offer{}
+	for rows.Next() {
+		offer, err := scanOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		offers = append(offers, *offer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+// GetCardOffers получает предложения по объявлению. Предложения видит только автор объявления
+func (repo *OffersDBRepository) GetCardOffers(cardID, userID string) ([]Offer, int, error) {
+	var sellerID string
+	err := repo.dtb.QueryRow("SELECT user_id FROM cards WHERE id = $1;", cardID).Scan(&sellerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение автора объявления: %v", err)
+	}
+
+	if sellerID != userID {
+		return nil, hdr.ForbiddenCode, fmt.Errorf("объявление принадлежит другому пользователю")
+	}
+
+	offers, err := repo.queryOffers("o.card_id = $1", cardID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, err
+	}
+	return offers, hdr.OKCode, nil
+}
+
+// GetUserOffers получает предложения, в которых пользователь является покупателем или продавцом
+func (repo *OffersDBRepository) GetUserOffers(userID string) ([]Offer, error) {
+	return repo.queryOffers("(o.buyer_id = $1 OR o.seller_id = $1)", userID)
+}
@@ -0,0 +1,66 @@
+package offers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/cards"
+	hdr "marketplace/internal/handlers"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation — код ошибки PostgreSQL о нарушении ограничения уникальности
+const uniqueViolation pq.ErrorCode = "23505"
+
+// MakeOffer создает предложение цены покупателя по опубликованному объявлению.
+// Предложенная цена не может превышать цену объявления, а у покупателя может быть
+// только одно ожидающее ответа предложение по объявлению
+func (repo *OffersDBRepository) MakeOffer(cardID, buyerID string, amount float64) (*Offer, int, error) {
+	var sellerID string
+	var price float64
+	query := `SELECT user_id, price FROM cards WHERE id = $1 AND status = $2 AND expires_at > NOW();`
+	err := repo.dtb.QueryRow(query, cardID, cards.StatusPublished).Scan(&sellerID, &price)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение объявления: %v", err)
+	}
+
+	if sellerID == buyerID {
+		return nil, hdr.BadRequestCode, fmt.Errorf("ошибка: нельзя сделать предложение по своему объявлению")
+	}
+	if amount > price {
+		return nil, hdr.BadRequestCode, fmt.Errorf("ошибка: предложенная цена не может превышать цену объявления")
+	}
+
+	// Истекшее предложение не должно мешать новому
+	query = `UPDATE offers SET status = $3 WHERE card_id = $1 AND buyer_id = $2 AND status = $4 AND expires_at <= NOW();`
+	_, err = repo.dtb.Exec(query, cardID, buyerID, StatusExpired, StatusPending)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: отметка истекших предложений: %v", err)
+	}
+
+	var offerID string
+	query = `
+        INSERT INTO offers (card_id, buyer_id, seller_id, proposed_by, amount, expires_at)
+        VALUES ($1, $2, $3, $2, $4, NOW() + $5 * INTERVAL '1 hour')
+        RETURNING id;
+    `
+	err = repo.dtb.QueryRow(query, cardID, buyerID, sellerID, amount, repo.ttlHours).Scan(&offerID)
+	if err != nil {
+		code, err := insertError(err)
+		return nil, code, err
+	}
+	return repo.getOffer(offerID)
+}
+
+// insertError получает код состояния ответа и описание ошибки создания предложения
+func insertError(err error) (int, error) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return hdr.ConflictCode, fmt.Errorf("по объявлению уже есть предложение, ожидающее ответа")
+	}
+	return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: создание предложения: %v", err)
+}
@@ -0,0 +1,64 @@
+package offers
+
+import "time"
+
+// Статусы предложения цены
+const (
+	// StatusPending — ожидает ответа
+	StatusPending string = "pending"
+	// StatusAccepted — принято, объявление зарезервировано до создания заказа
+	StatusAccepted string = "accepted"
+	// StatusOrdered — по принятому предложению создан заказ
+	StatusOrdered string = "ordered"
+	// StatusRejected — отклонено
+	StatusRejected string = "rejected"
+	// StatusCountered — в ответ сделано встречное предложение
+	StatusCountered string = "countered"
+	// StatusExpired — истек срок действия
+	StatusExpired string = "expired"
+	// StatusCancelled — заказ по предложению отменен, предложение нельзя использовать повторно
+	StatusCancelled string = "cancelled"
+)
+
+// Offer — предложение цены по объявлению
+type Offer struct {
+	// ID — идентификатор предложения
+	ID string `json:"id"`
+	// CardID — объявление
+	CardID string `json:"card_id"`
+	// CardTitle — заголовок объявления
+	CardTitle string `json:"card_title"`
+	// CardPrice — цена объявления
+	CardPrice float64 `json:"card_price"`
+	// Buyer — логин покупателя
+	Buyer string `json:"buyer"`
+	// Seller — логин продавца
+	Seller string `json:"seller"`
+	// ProposedBy — логин автора предложения
+	ProposedBy string `json:"proposed_by"`
+	// ParentID — предложение, в ответ на которое сделано встречное предложение
+	ParentID *string `json:"parent_id,omitempty"`
+	// Amount — предложенная цена
+	Amount float64 `json:"amount"`
+	// Status — статус предложения
+	Status string `json:"status"`
+	// CreatedAt — дата создания
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt — дата истечения срока действия: для принятого предложения — срок создания заказа
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type OffersRepo interface {
+	// MakeOffer создает предложение цены покупателя по объявлению
+	MakeOffer(cardID, buyerID string, amount float64) (*Offer, int, error)
+	// GetCardOffers получает предложения по объявлению, автором которого является пользователь
+	GetCardOffers(cardID, userID string) ([]Offer, int, error)
+	// GetUserOffers получает предложения, в которых пользователь является покупателем или продавцом
+	GetUserOffers(userID string) ([]Offer, error)
+	// AcceptOffer принимает предложение и резервирует объявление
+	AcceptOffer(offerID, userID string) (*Offer, int, error)
+	// RejectOffer отклоняет предложение
+	RejectOffer(offerID, userID string) (*Offer, int, error)
+	// CounterOffer отвечает на предложение встречным предложением
+	CounterOffer(offerID, userID string, amount float64) (*Offer, int, error)
+}
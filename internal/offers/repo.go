@@ -0,0 +1,36 @@
+package offers
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+)
+
+// defaultTTLHours — срок действия предложения в часах по умолчанию
+const defaultTTLHours int = 48
+
+type OffersDBRepository struct {
+	dtb *sql.DB
+	// ttlHours — срок действия предложения в часах
+	ttlHours int
+}
+
+func NewDBRepo(sdb *sql.DB) *OffersDBRepository {
+	return &OffersDBRepository{dtb: sdb, ttlHours: getTTLHours()}
+}
+
+// getTTLHours получает срок действия предложения из переменной окружения OFFER_TTL_HOURS
+func getTTLHours() int {
+	ttlParam := os.Getenv("OFFER_TTL_HOURS")
+	if ttlParam == "" {
+		return defaultTTLHours
+	}
+
+	ttl, err := strconv.Atoi(ttlParam)
+	if err != nil || ttl <= 0 {
+		log.Printf("invalid OFFER_TTL_HOURS value %q, using the default value %d\n", ttlParam, defaultTTLHours)
+		return defaultTTLHours
+	}
+	return ttl
+}
@@ -0,0 +1,171 @@
+package offers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/cards"
+	hdr "marketplace/internal/handlers"
+)
+
+// pendingOffer — ожидающее ответа предложение, заблокированное в транзакции
+type pendingOffer struct {
+	cardID   string
+	buyerID  string
+	sellerID string
+}
+
+// lockPendingOffer блокирует предложение, на которое отвечает пользователь. Ответить может
+// только второй участник сделки: продавец — на предложение покупателя, покупатель — на встречное предложение
+func lockPendingOffer(tx *sql.Tx, offerID, userID string) (*pendingOffer, int, error) {
+	var offer pendingOffer
+	var proposedBy, status string
+	var isExpired bool
+	query := `
+        SELECT card_id, buyer_id, seller_id, proposed_by, status, expires_at <= NOW()
+        FROM offers WHERE id = $1 FOR UPDATE;
+    `
+	err := tx.QueryRow(query, offerID).Scan(&offer.cardID, &offer.buyerID, &offer.sellerID, &proposedBy, &status, &isExpired)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("предложение не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение предложения: %v", err)
+	}
+
+	if userID != offer.buyerID && userID != offer.sellerID {
+		return nil, hdr.ForbiddenCode, fmt.Errorf("предложение относится к сделке других пользователей")
+	}
+	if userID == proposedBy {
+		return nil, hdr.ForbiddenCode, fmt.Errorf("нельзя ответить на свое предложение")
+	}
+	if status == StatusPending && isExpired {
+		status = StatusExpired
+	}
+	if status != StatusPending {
+		return nil, hdr.ConflictCode, fmt.Errorf("предложение со статусом %s не ожидает ответа", status)
+	}
+	return &offer, hdr.OKCode, nil
+}
+
+// AcceptOffer принимает предложение и переводит объявление в статус reserved.
+// Покупатель должен создать заказ по принятому предложению в течение срока действия предложения,
+// иначе резерв снимается. Остальные ожидающие ответа предложения по объявлению отклоняются
+func (repo *OffersDBRepository) AcceptOffer(offerID, userID string) (*Offer, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	offer, code, err := lockPendingOffer(tx, offerID, userID)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	var cardStatus string
+	var isCardExpired bool
+	query := "SELECT status, expires_at <= NOW() FROM cards WHERE id = $1 FOR UPDATE;"
+	err = tx.QueryRow(query, offer.cardID).Scan(&cardStatus, &isCardExpired)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение статуса объявления: %v", err)
+	}
+	if !cards.CanTransition(cardStatus, cards.StatusReserved) {
+		return nil, hdr.ConflictCode, fmt.Errorf("объявление со статусом %s не может быть зарезервировано", cardStatus)
+	}
+	if isCardExpired {
+		return nil, hdr.ConflictCode, fmt.Errorf("срок жизни объявления истек")
+	}
+
+	_, err = tx.Exec("UPDATE cards SET status = $1 WHERE id = $2;", cards.StatusReserved, offer.cardID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: резервирование объявления: %v", err)
+	}
+
+	query = `UPDATE offers SET status = $1, expires_at = NOW() + $2 * INTERVAL '1 hour' WHERE id = $3;`
+	_, err = tx.Exec(query, StatusAccepted, repo.ttlHours, offerID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: принятие предложения: %v", err)
+	}
+
+	query = `UPDATE offers SET status = $1 WHERE card_id = $2 AND status = $3 AND id <> $4;`
+	_, err = tx.Exec(query, StatusRejected, offer.cardID, StatusPending, offerID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: отклонение остальных предложений: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return repo.getOffer(offerID)
+}
+
+// RejectOffer отклоняет предложение
+func (repo *OffersDBRepository) RejectOffer(offerID, userID string) (*Offer, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, code, err := lockPendingOffer(tx, offerID, userID)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	_, err = tx.Exec("UPDATE offers SET status = $1 WHERE id = $2;", StatusRejected, offerID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: отклонение предложения: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return repo.getOffer(offerID)
+}
+
+// CounterOffer отвечает на предложение встречным предложением с новой ценой.
+// Исходное предложение получает статус countered, встречное ожидает ответа второго участника
+func (repo *OffersDBRepository) CounterOffer(offerID, userID string, amount float64) (*Offer, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	offer, code, err := lockPendingOffer(tx, offerID, userID)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	var price float64
+	err = tx.QueryRow("SELECT price FROM cards WHERE id = $1;", offer.cardID).Scan(&price)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение объявления: %v", err)
+	}
+	if amount > price {
+		return nil, hdr.BadRequestCode, fmt.Errorf("ошибка: предложенная цена не может превышать цену объявления")
+	}
+
+	_, err = tx.Exec("UPDATE offers SET status = $1 WHERE id = $2;", StatusCountered, offerID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: изменение статуса предложения: %v", err)
+	}
+
+	var counterID string
+	query := `
+        INSERT INTO offers (card_id, buyer_id, seller_id, proposed_by, parent_id, amount, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW() + $7 * INTERVAL '1 hour')
+        RETURNING id;
+    `
+	err = tx.QueryRow(query, offer.cardID, offer.buyerID, offer.sellerID, userID, offerID, amount, repo.ttlHours).Scan(&counterID)
+	if err != nil {
+		code, err := insertError(err)
+		return nil, code, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return repo.getOffer(counterID)
+}
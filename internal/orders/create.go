@@ -0,0 +1,180 @@
+package orders
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/cards"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/offers"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation — код ошибки PostgreSQL о нарушении ограничения уникальности
+const uniqueViolation pq.ErrorCode = "23505"
+
+// CreateOrder создает заказ по опубликованному объявлению по его цене.
+// Объявление переходит в статус reserved
+func (repo *OrdersDBRepository) CreateOrder(cardID, buyerID string) (*Order, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var sellerID string
+	var price float64
+	query := `SELECT user_id, price FROM cards WHERE id = $1 AND status = $2 AND expires_at > NOW() FOR UPDATE;`
+	err = tx.QueryRow(query, cardID, cards.StatusPublished).Scan(&sellerID, &price)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение объявления: %v", err)
+	}
+
+	if sellerID == buyerID {
+		return nil, hdr.BadRequestCode, fmt.Errorf("ошибка: нельзя купить свое объявление")
+	}
+
+	_, err = tx.Exec("UPDATE cards SET status = $1 WHERE id = $2;", cards.StatusReserved, cardID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: резервирование объявления: %v", err)
+	}
+
+	orderID, code, err := insertOrder(tx, cardID, nil, buyerID, sellerID, price, repo.ttlMinutes)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return repo.getOrder(orderID)
+}
+
+// CreateOrderFromOffer создает заказ по принятому предложению цены покупателя. Объявление должно быть
+// зарезервировано этим предложением или опубликовано, тогда оно резервируется. Предложение переходит
+// в статус ordered и не может быть использовано повторно
+func (repo *OrdersDBRepository) CreateOrderFromOffer(offerID, buyerID string) (*Order, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var cardID, offerBuyerID, sellerID, status string
+	var amount float64
+	var isExpired bool
+	query := `SELECT card_id, buyer_id, seller_id, status, amount, expires_at <= NOW() FROM offers WHERE id = $1 FOR UPDATE;`
+	err = tx.QueryRow(query, offerID).Scan(&cardID, &offerBuyerID, &sellerID, &status, &amount, &isExpired)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("предложение не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение предложения: %v", err)
+	}
+
+	if offerBuyerID != buyerID {
+		return nil, hdr.ForbiddenCode, fmt.Errorf("предложение сделано другим покупателем")
+	}
+	if status != offers.StatusAccepted {
+		return nil, hdr.ConflictCode, fmt.Errorf("заказ можно создать только по принятому предложению")
+	}
+	if isExpired {
+		return nil, hdr.ConflictCode, fmt.Errorf("срок создания заказа по предложению истек")
+	}
+
+	code, err := reserveForOffer(tx, cardID, offerID)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	orderID, code, err := insertOrder(tx, cardID, &offerID, buyerID, sellerID, amount, repo.ttlMinutes)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	_, err = tx.Exec("UPDATE offers SET status = $1 WHERE id = $2;", offers.StatusOrdered, offerID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: изменение статуса предложения: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return repo.getOrder(orderID)
+}
+
+// reserveForOffer блокирует объявление принятого предложения и проверяет, что его можно заказать.
+// Опубликованное объявление резервируется, зарезервированное не должно быть зарезервировано
+// другим принятым предложением. Незавершенный заказ другого покупателя отклоняется при создании заказа
+func reserveForOffer(tx *sql.Tx, cardID, offerID string) (int, error) {
+	var cardStatus string
+	var isCardExpired bool
+	query := "SELECT status, expires_at <= NOW() FROM cards WHERE id = $1 FOR UPDATE;"
+	err := tx.QueryRow(query, cardID).Scan(&cardStatus, &isCardExpired)
+	if errors.Is(err, sql.ErrNoRows) {
+		return hdr.NotFoundCode, fmt.Errorf("объявление не существует")
+	}
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение статуса объявления: %v", err)
+	}
+
+	switch cardStatus {
+	case cards.StatusPublished:
+		if isCardExpired {
+			return hdr.ConflictCode, fmt.Errorf("срок жизни объявления истек")
+		}
+		_, err = tx.Exec("UPDATE cards SET status = $1 WHERE id = $2;", cards.StatusReserved, cardID)
+		if err != nil {
+			return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: резервирование объявления: %v", err)
+		}
+	case cards.StatusReserved:
+		var isReservedByOther bool
+		query = `SELECT EXISTS (SELECT 1 FROM offers WHERE card_id = $1 AND status = $2 AND id <> $3);`
+		err = tx.QueryRow(query, cardID, offers.StatusAccepted, offerID).Scan(&isReservedByOther)
+		if err != nil {
+			return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: проверка резерва объявления: %v", err)
+		}
+		if isReservedByOther {
+			return hdr.ConflictCode, fmt.Errorf("объявление зарезервировано другим покупателем")
+		}
+	default:
+		return hdr.ConflictCode, fmt.Errorf("объявление со статусом %s нельзя заказать", cardStatus)
+	}
+	return hdr.OKCode, nil
+}
+
+// insertOrder добавляет заказ со сроком оплаты ttlMinutes минут.
+// По объявлению может быть только один незавершенный заказ
+func insertOrder(tx *sql.Tx, cardID string, offerID *string, buyerID, sellerID string, amount float64, ttlMinutes int) (string, int, error) {
+	var orderID string
+	query := `
+        INSERT INTO orders (card_id, offer_id, buyer_id, seller_id, amount, expires_at)
+        VALUES ($1, $2, $3, $4, $5, NOW() + $6 * INTERVAL '1 minute')
+        RETURNING id;
+    `
+	err := tx.QueryRow(query, cardID, offerID, buyerID, sellerID, amount, ttlMinutes).Scan(&orderID)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return "", hdr.ConflictCode, fmt.Errorf("по объявлению уже есть незавершенный заказ")
+	}
+	if err != nil {
+		return "", hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: создание заказа: %v", err)
+	}
+	return orderID, hdr.OKCode, nil
+}
+
+// SetPayment сохраняет платеж заказа
+func (repo *OrdersDBRepository) SetPayment(orderID, paymentID, paymentURL string) (*Order, error) {
+	query := `UPDATE orders SET payment_id = $2, payment_url = $3 WHERE id = $1;`
+	_, err := repo.dtb.Exec(query, orderID, paymentID, paymentURL)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: сохранение платежа заказа: %v", err)
+	}
+
+	order, _, err := repo.getOrder(orderID)
+	return order, err
+}
@@ -0,0 +1,62 @@
+package orders
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"marketplace/internal/cards"
+	"marketplace/internal/offers"
+	"time"
+)
+
+// ExpirySweepInterval — интервал между проверками неоплаченных заказов с истекшим сроком оплаты
+const ExpirySweepInterval = time.Minute
+
+// ExpireOrders отменяет ожидающие оплаты заказы с истекшим сроком оплаты, снова публикует
+// зарезервированные по ним объявления и закрывает предложения цены, по которым они созданы
+func (repo *OrdersDBRepository) ExpireOrders() (int64, error) {
+	query := `
+        WITH expired AS (
+            UPDATE orders SET status = $1, updated_at = NOW()
+            WHERE status = $2 AND expires_at <= NOW()
+            RETURNING card_id, offer_id
+        ), released AS (
+            UPDATE cards SET status = $3
+            WHERE status = $4 AND id IN (SELECT card_id FROM expired)
+            RETURNING id
+        ), closed AS (
+            UPDATE offers SET status = $5
+            WHERE status = $6 AND id IN (SELECT offer_id FROM expired)
+            RETURNING id
+        )
+        SELECT COUNT(*) FROM expired;
+    `
+	var count int64
+	err := repo.dtb.QueryRow(query, StatusCancelled, StatusPending, cards.StatusPublished, cards.StatusReserved,
+		offers.StatusCancelled, offers.StatusOrdered).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса к базе данных: отмена неоплаченных заказов: %v", err)
+	}
+	return count, nil
+}
+
+// RunExpirySweeper периодически отменяет неоплаченные заказы с истекшим сроком оплаты до отмены контекста
+func (repo *OrdersDBRepository) RunExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := repo.ExpireOrders()
+		if err != nil {
+			log.Printf("error while expiring orders: %v\n", err)
+		} else if count > 0 {
+			log.Printf("%d unpaid orders have expired\n", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package orders
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// selectOrders — запрос заказов с данными объявления и участников
+const selectOrders string = `
+    SELECT
+        o.id,
+        o.card_id,
+        c.title,
+        o.offer_id,
+        b.username,
+        s.username,
+        o.amount,
+        o.status,
+        o.payment_id,
+        o.payment_url,
+        o.refund_status,
+        o.created_at,
+        o.updated_at,
+        o.expires_at
+    FROM orders o
+    JOIN cards c ON c.id = o.card_id
+    JOIN users b ON b.id = o.buyer_id
+    JOIN users s ON s.id = o.seller_id
+`
+
+// rowScanner — строка или строки результата запроса
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanOrder считывает заказ из результата запроса selectOrders
+func scanOrder(row rowScanner) (*Order, error) {
+	var order Order
+	err := row.Scan(&order.ID, &order.CardID, &order.CardTitle, &order.OfferID, &order.Buyer, &order.Seller,
+		&order.Amount, &order.Status, &order.PaymentID, &order.PaymentURL, &order.RefundStatus, &order.CreatedAt, &order.UpdatedAt, &order.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// getOrder получает заказ по его идентификатору
+func (repo *OrdersDBRepository) getOrder(orderID string) (*Order, int, error) {
+	order, err := scanOrder(repo.dtb.QueryRow(selectOrders+" WHERE o.id = $1;", orderID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("заказ не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение заказа: %v", err)
+	}
+	return order, hdr.OKCode, nil
+}
+
+// GetOrder получает заказ. Заказ доступен только покупателю и продавцу
+func (repo *OrdersDBRepository) GetOrder(orderID, userID string) (*Order, int, error) {
+	var buyerID, sellerID string
+	query := `SELECT buyer_id, seller_id FROM orders WHERE id = $1;`
+	err := repo.dtb.QueryRow(query, orderID).Scan(&buyerID, &sellerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("заказ не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение заказа: %v", err)
+	}
+
+	if userID != buyerID && userID != sellerID {
+		return nil, hdr.ForbiddenCode, fmt.Errorf("заказ относится к сделке других пользователей")
+	}
+	return repo.getOrder(orderID)
+}
+
+// GetUserOrders получает заказы, в которых пользователь является покупателем или продавцом
+func (repo *OrdersDBRepository) GetUserOrders(userID string) ([]Order, error) {
+	query := selectOrders + " WHERE o.buyer_id = $1 OR o.seller_id = $1 ORDER BY o.created_at DESC, o.id DESC;"
+	rows, err := repo.dtb.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение заказов: %v", err)
+	}
+	defer rows.Close()
+
+	orders := []Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
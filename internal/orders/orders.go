@@ -0,0 +1,89 @@
+package orders
+
+import "time"
+
+// Статусы заказа
+const (
+	// StatusPending — ожидает оплаты
+	StatusPending string = "pending"
+	// StatusPaid — оплачен
+	StatusPaid string = "paid"
+	// StatusShipped — отправлен продавцом
+	StatusShipped string = "shipped"
+	// StatusCompleted — получен покупателем, сделка завершена
+	StatusCompleted string = "completed"
+	// StatusCancelled — отменен
+	StatusCancelled string = "cancelled"
+)
+
+// Статусы возврата платежа отмененного заказа
+const (
+	// RefundPending — платеж ожидает возврата
+	RefundPending string = "pending"
+	// RefundRefunded — платеж возвращен
+	RefundRefunded string = "refunded"
+)
+
+// Order — заказ покупателя по объявлению
+type Order struct {
+	// ID — идентификатор заказа
+	ID string `json:"id"`
+	// CardID — объявление
+	CardID string `json:"card_id"`
+	// CardTitle — заголовок объявления
+	CardTitle string `json:"card_title"`
+	// OfferID — принятое предложение цены, по которому создан заказ
+	OfferID *string `json:"offer_id,omitempty"`
+	// Buyer — логин покупателя
+	Buyer string `json:"buyer"`
+	// Seller — логин продавца
+	Seller string `json:"seller"`
+	// Amount — сумма заказа
+	Amount float64 `json:"amount"`
+	// Status — статус заказа
+	Status string `json:"status"`
+	// PaymentID — идентификатор платежа у платежного провайдера
+	PaymentID *string `json:"payment_id,omitempty"`
+	// PaymentURL — адрес страницы оплаты
+	PaymentURL *string `json:"payment_url,omitempty"`
+	// RefundStatus — статус возврата платежа отмененного заказа
+	RefundStatus *string `json:"refund_status,omitempty"`
+	// CreatedAt — дата создания
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt — дата последнего изменения статуса
+	UpdatedAt time.Time `json:"updated_at"`
+	// ExpiresAt — срок оплаты, после которого неоплаченный заказ отменяется
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Refund — платеж отмененного заказа, который нужно вернуть покупателю
+type Refund struct {
+	// OrderID — заказ
+	OrderID string
+	// PaymentID — идентификатор платежа у платежного провайдера
+	PaymentID string
+}
+
+type OrdersRepo interface {
+	// CreateOrder создает заказ по опубликованному объявлению по его цене
+	CreateOrder(cardID, buyerID string) (*Order, int, error)
+	// CreateOrderFromOffer создает заказ по принятому предложению цены
+	CreateOrderFromOffer(offerID, buyerID string) (*Order, int, error)
+	// SetPayment сохраняет платеж заказа
+	SetPayment(orderID, paymentID, paymentURL string) (*Order, error)
+	// MarkPaid отмечает оплаченным заказ с указанным платежом.
+	// Для отмененного заказа возвращает платеж, который нужно вернуть покупателю
+	MarkPaid(paymentID string) (*Refund, int, error)
+	// MarkFailed отменяет ожидающий оплаты заказ с непроведенным платежом
+	MarkFailed(paymentID string) (int, error)
+	// GetOrder получает заказ, участником которого является пользователь
+	GetOrder(orderID, userID string) (*Order, int, error)
+	// GetUserOrders получает заказы, в которых пользователь является покупателем или продавцом
+	GetUserOrders(userID string) ([]Order, error)
+	// ChangeStatus изменяет статус заказа, участником которого является пользователь
+	ChangeStatus(orderID, userID, status string) (*Order, int, error)
+	// GetPendingRefunds получает платежи отмененных заказов, ожидающие возврата
+	GetPendingRefunds() ([]Refund, error)
+	// MarkRefunded отмечает платеж заказа возвращенным
+	MarkRefunded(orderID string) error
+}
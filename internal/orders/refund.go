@@ -0,0 +1,76 @@
+package orders
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RefundSweepInterval — интервал между повторными попытками возврата платежей
+const RefundSweepInterval = 5 * time.Minute
+
+// requestRefund отмечает платеж заказа ожидающим возврата
+func requestRefund(tx *sql.Tx, orderID string) error {
+	_, err := tx.Exec("UPDATE orders SET refund_status = $1 WHERE id = $2;", RefundPending, orderID)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к базе данных: запрос возврата платежа: %v", err)
+	}
+	return nil
+}
+
+// requestLateRefund отмечает ожидающим возврата платеж, проведенный после отмены заказа.
+// Если возврат уже запрошен повторным уведомлением, возвращает nil
+func requestLateRefund(tx *sql.Tx, orderID, paymentID string) (*Refund, error) {
+	query := `UPDATE orders SET refund_status = $1 WHERE id = $2 AND refund_status IS NULL;`
+	res, err := tx.Exec(query, RefundPending, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: запрос возврата платежа: %v", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: запрос возврата платежа: %v", err)
+	}
+	if affected == 0 {
+		return nil, nil
+	}
+	return &Refund{OrderID: orderID, PaymentID: paymentID}, nil
+}
+
+// GetPendingRefunds получает платежи отмененных заказов, ожидающие возврата.
+// Заказы, отмененные меньше минуты назад, пропускаются: их платежи возвращаются при отмене
+func (repo *OrdersDBRepository) GetPendingRefunds() ([]Refund, error) {
+	query := `
+        SELECT id, payment_id FROM orders
+        WHERE refund_status = $1 AND updated_at <= NOW() - INTERVAL '1 minute'
+        ORDER BY updated_at;
+    `
+	rows, err := repo.dtb.Query(query, RefundPending)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение ожидающих возврата платежей: %v", err)
+	}
+	defer rows.Close()
+
+	refunds := []Refund{}
+	for rows.Next() {
+		var rfd Refund
+		if err := rows.Scan(&rfd.OrderID, &rfd.PaymentID); err != nil {
+			return nil, fmt.Errorf("ошибка запроса к базе данных: получение ожидающих возврата платежей: %v", err)
+		}
+		refunds = append(refunds, rfd)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// MarkRefunded отмечает платеж заказа возвращенным
+func (repo *OrdersDBRepository) MarkRefunded(orderID string) error {
+	query := `UPDATE orders SET refund_status = $1 WHERE id = $2 AND refund_status = $3;`
+	_, err := repo.dtb.Exec(query, RefundRefunded, orderID, RefundPending)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к базе данных: отметка возврата платежа: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package orders
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+)
+
+// defaultTTLMinutes — срок оплаты заказа в минутах по умолчанию
+const defaultTTLMinutes int = 30
+
+type OrdersDBRepository struct {
+	dtb *sql.DB
+	// ttlMinutes — срок оплаты заказа в минутах
+	ttlMinutes int
+}
+
+func NewDBRepo(sdb *sql.DB) *OrdersDBRepository {
+	return &OrdersDBRepository{dtb: sdb, ttlMinutes: getTTLMinutes()}
+}
+
+// getTTLMinutes получает срок оплаты заказа из переменной окружения ORDER_TTL_MINUTES
+func getTTLMinutes() int {
+	ttlParam := os.Getenv("ORDER_TTL_MINUTES")
+	if ttlParam == "" {
+		return defaultTTLMinutes
+	}
+
+	ttl, err := strconv.Atoi(ttlParam)
+	if err != nil || ttl <= 0 {
+		log.Printf("invalid ORDER_TTL_MINUTES value %q, using the default value %d\n", ttlParam, defaultTTLMinutes)
+		return defaultTTLMinutes
+	}
+	return ttl
+}
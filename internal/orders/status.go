@@ -0,0 +1,206 @@
+package orders
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/cards"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/offers"
+)
+
+// Участники заказа, которым разрешен переход статуса
+const (
+	// RoleBuyer — покупатель
+	RoleBuyer string = "buyer"
+	// RoleSeller — продавец
+	RoleSeller string = "seller"
+	// RoleAny — любой из участников
+	RoleAny string = "any"
+)
+
+// transitions — допустимые переходы между статусами заказа и участники, которые могут их выполнить.
+// Переход в статус paid выполняется только по уведомлению платежного провайдера
+var transitions = map[string]map[string]string{
+	StatusPending:   {StatusCancelled: RoleAny},
+	StatusPaid:      {StatusShipped: RoleSeller, StatusCancelled: RoleSeller},
+	StatusShipped:   {StatusCompleted: RoleBuyer},
+	StatusCompleted: {},
+	StatusCancelled: {},
+}
+
+// IsValidStatus проверяет, что статус заказа существует
+func IsValidStatus(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// CanTransition проверяет, может ли участник заказа с ролью role перевести его из статуса from в статус to
+func CanTransition(from, to, role string) bool {
+	allowed, ok := transitions[from][to]
+	return ok && (allowed == RoleAny || allowed == role)
+}
+
+// ChangeStatus изменяет статус заказа, участником которого является пользователь.
+// При завершении заказа объявление переходит в статус sold, при отмене — снова публикуется.
+// Платеж отменяемого оплаченного заказа в той же транзакции отмечается ожидающим возврата
+func (repo *OrdersDBRepository) ChangeStatus(orderID, userID, status string) (*Order, int, error) {
+	if !IsValidStatus(status) {
+		return nil, hdr.BadRequestCode, fmt.Errorf("неизвестный статус заказа: %q", status)
+	}
+
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var cardID, buyerID, sellerID, current string
+	var paymentID *string
+	query := `SELECT card_id, buyer_id, seller_id, status, payment_id FROM orders WHERE id = $1 FOR UPDATE;`
+	err = tx.QueryRow(query, orderID).Scan(&cardID, &buyerID, &sellerID, &current, &paymentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("заказ не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение статуса заказа: %v", err)
+	}
+
+	var role string
+	switch userID {
+	case buyerID:
+		role = RoleBuyer
+	case sellerID:
+		role = RoleSeller
+	default:
+		return nil, hdr.ForbiddenCode, fmt.Errorf("заказ относится к сделке других пользователей")
+	}
+
+	if !CanTransition(current, status, role) {
+		return nil, hdr.ConflictCode, fmt.Errorf("недопустимый переход статуса заказа: %s -> %s", current, status)
+	}
+
+	if err := updateStatus(tx, orderID, cardID, status); err != nil {
+		return nil, hdr.InternalServerErrorCode, err
+	}
+
+	if status == StatusCancelled && current == StatusPaid && paymentID != nil {
+		if err := requestRefund(tx, orderID); err != nil {
+			return nil, hdr.InternalServerErrorCode, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return repo.getOrder(orderID)
+}
+
+// MarkPaid отмечает оплаченным ожидающий оплаты заказ с указанным платежом.
+// Повторное уведомление об уже оплаченном заказе не является ошибкой. Платеж, проведенный
+// после отмены заказа, отмечается ожидающим возврата и возвращается вызывающей стороне для возврата
+func (repo *OrdersDBRepository) MarkPaid(paymentID string) (*Refund, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	orderID, cardID, current, code, err := lockByPayment(tx, paymentID)
+	if code != hdr.OKCode {
+		return nil, code, err
+	}
+
+	var refund *Refund
+	switch current {
+	case StatusPending:
+		err = updateStatus(tx, orderID, cardID, StatusPaid)
+	case StatusCancelled:
+		refund, err = requestLateRefund(tx, orderID, paymentID)
+	default:
+		return nil, hdr.OKCode, nil
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return refund, hdr.OKCode, nil
+}
+
+// MarkFailed отменяет ожидающий оплаты заказ с непроведенным платежом, объявление снова публикуется.
+// Уведомление о заказе в другом статусе не является ошибкой и ничего не изменяет
+func (repo *OrdersDBRepository) MarkFailed(paymentID string) (int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	orderID, cardID, current, code, err := lockByPayment(tx, paymentID)
+	if code != hdr.OKCode {
+		return code, err
+	}
+	if current != StatusPending {
+		return hdr.OKCode, nil
+	}
+
+	if err := updateStatus(tx, orderID, cardID, StatusCancelled); err != nil {
+		return hdr.InternalServerErrorCode, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return hdr.OKCode, nil
+}
+
+// lockByPayment блокирует заказ с указанным платежом и получает его объявление и статус
+func lockByPayment(tx *sql.Tx, paymentID string) (string, string, string, int, error) {
+	var orderID, cardID, current string
+	query := `SELECT id, card_id, status FROM orders WHERE payment_id = $1 FOR UPDATE;`
+	err := tx.QueryRow(query, paymentID).Scan(&orderID, &cardID, &current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", "", hdr.NotFoundCode, fmt.Errorf("заказ с платежом %s не существует", paymentID)
+	}
+	if err != nil {
+		return "", "", "", hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение заказа: %v", err)
+	}
+	return orderID, cardID, current, hdr.OKCode, nil
+}
+
+// updateStatus изменяет статус заказа и соответствующий ему статус зарезервированного объявления.
+// Предложение цены отмененного заказа переходит в статус cancelled
+func updateStatus(tx *sql.Tx, orderID, cardID, status string) error {
+	_, err := tx.Exec("UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2;", status, orderID)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к базе данных: изменение статуса заказа: %v", err)
+	}
+
+	var cardStatus string
+	switch status {
+	case StatusCompleted:
+		cardStatus = cards.StatusSold
+	case StatusCancelled:
+		cardStatus = cards.StatusPublished
+	default:
+		return nil
+	}
+
+	query := `UPDATE cards SET status = $1 WHERE id = $2 AND status = $3;`
+	_, err = tx.Exec(query, cardStatus, cardID, cards.StatusReserved)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к базе данных: изменение статуса объявления: %v", err)
+	}
+
+	if status == StatusCancelled {
+		query = `UPDATE offers SET status = $1 WHERE status = $2 AND id = (SELECT offer_id FROM orders WHERE id = $3);`
+		_, err = tx.Exec(query, offers.StatusCancelled, offers.StatusOrdered, orderID)
+		if err != nil {
+			return fmt.Errorf("ошибка запроса к базе данных: изменение статуса предложения: %v", err)
+		}
+	}
+	return nil
+}
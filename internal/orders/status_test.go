@@ -0,0 +1,32 @@
+package orders_test
+
+import (
+	"marketplace/internal/orders"
+	"testing"
+)
+
+// TestCanTransition тестирует допустимые и недопустимые переходы между статусами заказа
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to, role string
+		result         bool
+	}{
+		{orders.StatusPending, orders.StatusCancelled, orders.RoleBuyer, true},
+		{orders.StatusPending, orders.StatusCancelled, orders.RoleSeller, true},
+		{orders.StatusPaid, orders.StatusShipped, orders.RoleSeller, true},
+		{orders.StatusPaid, orders.StatusCancelled, orders.RoleSeller, true},
+		{orders.StatusShipped, orders.StatusCompleted, orders.RoleBuyer, true},
+		{orders.StatusPending, orders.StatusPaid, orders.RoleBuyer, false},
+		{orders.StatusPaid, orders.StatusShipped, orders.RoleBuyer, false},
+		{orders.StatusPaid, orders.StatusCancelled, orders.RoleBuyer, false},
+		{orders.StatusShipped, orders.StatusCompleted, orders.RoleSeller, false},
+		{orders.StatusCompleted, orders.StatusCancelled, orders.RoleSeller, false},
+		{"unknown", orders.StatusCancelled, orders.RoleBuyer, false},
+	}
+
+	for _, test := range tests {
+		if got := orders.CanTransition(test.from, test.to, test.role); got != test.result {
+			t.Errorf("%s -> %s (%s): ожидалось %t, но получено %t", test.from, test.to, test.role, test.result, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider — платежный провайдер в памяти процесса для разработки и тестов.
+// Уведомления подписываются HMAC-SHA256 с общим секретом
+type FakeProvider struct {
+	mtx      sync.Mutex
+	secret   []byte
+	payments map[string]*fakePayment
+}
+
+// fakePayment — платеж фиктивного провайдера
+type fakePayment struct {
+	orderID  string
+	amount   float64
+	status   string
+	refunded bool
+}
+
+func NewFakeProvider(secret []byte) *FakeProvider {
+	return &FakeProvider{secret: secret, payments: make(map[string]*fakePayment)}
+}
+
+// CreatePayment создает платеж по заказу
+func (prv *FakeProvider) CreatePayment(orderID string, amount float64) (*Payment, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("сумма платежа должна быть выше 0")
+	}
+
+	prv.mtx.Lock()
+	defer prv.mtx.Unlock()
+
+	paymentID := uuid.NewString()
+	prv.payments[paymentID] = &fakePayment{orderID: orderID, amount: amount}
+	return &Payment{ID: paymentID, URL: "https://pay.example.com/" + paymentID}, nil
+}
+
+// Refund возвращает проведенный платеж
+func (prv *FakeProvider) Refund(paymentID string) error {
+	prv.mtx.Lock()
+	defer prv.mtx.Unlock()
+
+	payment, ok := prv.payments[paymentID]
+	if !ok {
+		return fmt.Errorf("платеж %s не существует", paymentID)
+	}
+	if payment.status != StatusSucceeded {
+		return fmt.Errorf("платеж %s не проведен", paymentID)
+	}
+	payment.refunded = true
+	return nil
+}
+
+// IsRefunded проверяет, был ли платеж возвращен
+func (prv *FakeProvider) IsRefunded(paymentID string) bool {
+	prv.mtx.Lock()
+	defer prv.mtx.Unlock()
+
+	payment, ok := prv.payments[paymentID]
+	return ok && payment.refunded
+}
+
+// Complete проводит платеж с указанным статусом и возвращает подписанное уведомление,
+// которое провайдер отправил бы на адрес уведомлений
+func (prv *FakeProvider) Complete(paymentID, status string) ([]byte, string, error) {
+	prv.mtx.Lock()
+	payment, ok := prv.payments[paymentID]
+	if ok {
+		payment.status = status
+	}
+	prv.mtx.Unlock()
+
+	if !ok {
+		return nil, "", fmt.Errorf("платеж %s не существует", paymentID)
+	}
+
+	body, err := json.Marshal(WebhookEvent{PaymentID: paymentID, Status: status})
+	if err != nil {
+		return nil, "", err
+	}
+	return body, prv.Sign(body), nil
+}
+
+// Sign подписывает тело уведомления
+func (prv *FakeProvider) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, prv.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseWebhook проверяет подпись уведомления и разбирает его
+func (prv *FakeProvider) ParseWebhook(body []byte, signature string) (*WebhookEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, prv.secret)
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return nil, ErrInvalidSignature
+	}
+
+	var evt WebhookEvent
+	if err := json.Unmarshal(body, &evt); err != nil || evt.PaymentID == "" {
+		return nil, fmt.Errorf("ошибка: некорректное уведомление платежного провайдера")
+	}
+	return &evt, nil
+}
@@ -0,0 +1,48 @@
+package payments
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFakeProviderWebhook(t *testing.T) {
+	prv := NewFakeProvider([]byte("secret"))
+	payment, err := prv.CreatePayment("order", 100)
+	if err != nil {
+		t.Fatalf("Ошибка создания платежа: %v", err)
+	}
+
+	body, signature, err := prv.Complete(payment.ID, StatusSucceeded)
+	if err != nil {
+		t.Fatalf("Ошибка проведения платежа: %v", err)
+	}
+
+	evt, err := prv.ParseWebhook(body, signature)
+	if err != nil {
+		t.Fatalf("Ошибка проверки уведомления: %v", err)
+	}
+	if evt.PaymentID != payment.ID || evt.Status != StatusSucceeded {
+		t.Errorf("Получено неожиданное уведомление: %+v", evt)
+	}
+
+	tests := map[string]struct {
+		body      []byte
+		signature string
+	}{
+		"подпись другим секретом": {body, NewFakeProvider([]byte("other")).Sign(body)},
+		"измененное тело":         {append(body[:len(body):len(body)], ' '), signature},
+		"подпись не в hex":        {body, "not-hex"},
+		"пустая подпись":          {body, ""},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := prv.ParseWebhook(tc.body, tc.signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Ожидалась ошибка %v, но получена: %v", ErrInvalidSignature, err)
+			}
+		})
+	}
+
+	if err := prv.Refund(payment.ID); err != nil || !prv.IsRefunded(payment.ID) {
+		t.Errorf("Ожидался возврат платежа, ошибка: %v", err)
+	}
+}
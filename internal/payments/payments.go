@@ -0,0 +1,43 @@
+package payments
+
+import "errors"
+
+// Статусы платежа в уведомлениях платежного провайдера
+const (
+	// StatusSucceeded — платеж проведен
+	StatusSucceeded string = "succeeded"
+	// StatusFailed — платеж не проведен
+	StatusFailed string = "failed"
+)
+
+// SignatureHeader — заголовок с подписью уведомления платежного провайдера
+const SignatureHeader string = "X-Signature"
+
+// ErrInvalidSignature — подпись уведомления платежного провайдера не прошла проверку
+var ErrInvalidSignature = errors.New("некорректная подпись уведомления")
+
+// Payment — платеж, созданный платежным провайдером
+type Payment struct {
+	// ID — идентификатор платежа у провайдера
+	ID string `json:"id"`
+	// URL — адрес страницы оплаты
+	URL string `json:"url"`
+}
+
+// WebhookEvent — уведомление платежного провайдера об изменении статуса платежа
+type WebhookEvent struct {
+	// PaymentID — идентификатор платежа у провайдера
+	PaymentID string `json:"payment_id"`
+	// Status — статус платежа
+	Status string `json:"status"`
+}
+
+// PaymentProvider — платежный провайдер
+type PaymentProvider interface {
+	// CreatePayment создает платеж по заказу
+	CreatePayment(orderID string, amount float64) (*Payment, error)
+	// Refund возвращает проведенный платеж
+	Refund(paymentID string) error
+	// ParseWebhook проверяет подпись уведомления и разбирает его
+	ParseWebhook(body []byte, signature string) (*WebhookEvent, error)
+}
@@ -0,0 +1,36 @@
+package payments
+
+import (
+	"fmt"
+	"os"
+)
+
+// Платежные провайдеры, выбираемые переменной окружения PAYMENT_PROVIDER
+const (
+	// ProviderFake — фиктивный провайдер в памяти процесса
+	ProviderFake string = "fake"
+)
+
+// devEnvironments — окружения (переменная APP_ENV), в которых допускается фиктивный провайдер
+var devEnvironments = map[string]bool{"dev": true, "test": true}
+
+// NewProviderFromEnv создает платежного провайдера, выбранного переменной окружения PAYMENT_PROVIDER.
+// Фиктивный провайдер теряет платежи при перезапуске, поэтому допускается только при APP_ENV=dev или test
+func NewProviderFromEnv() (PaymentProvider, error) {
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("не задан секрет уведомлений платежного провайдера PAYMENT_WEBHOOK_SECRET")
+	}
+
+	switch provider := os.Getenv("PAYMENT_PROVIDER"); provider {
+	case "":
+		return nil, fmt.Errorf("не задан платежный провайдер PAYMENT_PROVIDER")
+	case ProviderFake:
+		if env := os.Getenv("APP_ENV"); !devEnvironments[env] {
+			return nil, fmt.Errorf("фиктивный платежный провайдер допускается только при APP_ENV=dev или APP_ENV=test, получено APP_ENV=%q", env)
+		}
+		return NewFakeProvider([]byte(secret)), nil
+	default:
+		return nil, fmt.Errorf("неизвестный платежный провайдер %q", provider)
+	}
+}
@@ -0,0 +1,33 @@
+package payments
+
+import "testing"
+
+func TestNewProviderFromEnv(t *testing.T) {
+	tests := []struct {
+		name, provider, env, secret string
+		ok                          bool
+	}{
+		{"фиктивный провайдер в разработке", ProviderFake, "dev", "secret", true},
+		{"фиктивный провайдер в тестах", ProviderFake, "test", "secret", true},
+		{"фиктивный провайдер в продакшене", ProviderFake, "prod", "secret", false},
+		{"фиктивный провайдер без окружения", ProviderFake, "", "secret", false},
+		{"провайдер не задан", "", "dev", "secret", false},
+		{"неизвестный провайдер", "unknown", "dev", "secret", false},
+		{"без секрета уведомлений", ProviderFake, "dev", "", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("PAYMENT_PROVIDER", tc.provider)
+			t.Setenv("APP_ENV", tc.env)
+			t.Setenv("PAYMENT_WEBHOOK_SECRET", tc.secret)
+
+			prv, err := NewProviderFromEnv()
+			if tc.ok && (err != nil || prv == nil) {
+				t.Errorf("Ожидался провайдер, но получена ошибка: %v", err)
+			}
+			if !tc.ok && err == nil {
+				t.Errorf("Ожидалась ошибка, но получен провайдер %T", prv)
+			}
+		})
+	}
+}
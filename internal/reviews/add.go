@@ -0,0 +1,52 @@
+package reviews
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+	"marketplace/internal/orders"
+)
+
+// AddReview добавляет отзыв покупателя о продавце. Отзыв можно оставить только по завершенному
+// заказу у этого продавца; по каждому заказу — один отзыв, используется самый ранний заказ без отзыва
+func (repo *ReviewsDBRepository) AddReview(seller, reviewerID string, rating int, text string) (*Review, int, error) {
+	var sellerID string
+	err := repo.dtb.QueryRow("SELECT id FROM users WHERE username = $1;", seller).Scan(&sellerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("пользователь не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение пользователя: %v", err)
+	}
+
+	if sellerID == reviewerID {
+		return nil, hdr.BadRequestCode, fmt.Errorf("ошибка: нельзя оставить отзыв о себе")
+	}
+
+	var reviewID string
+	query := `
+        INSERT INTO reviews (order_id, reviewer_id, seller_id, rating, review_text)
+        SELECT o.id, o.buyer_id, o.seller_id, $3, $4
+        FROM orders o
+        WHERE o.buyer_id = $1 AND o.seller_id = $2 AND o.status = $5
+            AND NOT EXISTS (SELECT 1 FROM reviews r WHERE r.order_id = o.id)
+        ORDER BY o.updated_at, o.id
+        LIMIT 1
+        ON CONFLICT (order_id) DO NOTHING
+        RETURNING id;
+    `
+	err = repo.dtb.QueryRow(query, reviewerID, sellerID, rating, text, orders.StatusCompleted).Scan(&reviewID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.ForbiddenCode, fmt.Errorf("отзыв можно оставить только после завершенной сделки с продавцом")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: добавление отзыва: %v", err)
+	}
+
+	review, err := scanReview(repo.dtb.QueryRow(selectReviews+" WHERE r.id = $1;", reviewID))
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение отзыва: %v", err)
+	}
+	return review, hdr.OKCode, nil
+}
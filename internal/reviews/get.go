@@ -0,0 +1,72 @@
+package reviews
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// selectReviews — запрос отзывов с данными заказа и участников
+const selectReviews string = `
+    SELECT r.id, r.order_id, c.title, ru.username, su.username, r.rating, r.review_text, r.created_at
+    FROM reviews r
+    JOIN orders o ON o.id = r.order_id
+    JOIN cards c ON c.id = o.card_id
+    JOIN users ru ON ru.id = r.reviewer_id
+    JOIN users su ON su.id = r.seller_id
+`
+
+// rowScanner — строка или строки результата запроса
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanReview считывает отзыв из результата запроса selectReviews
+func scanReview(row rowScanner) (*Review, error) {
+	var review Review
+	err := row.Scan(&review.ID, &review.OrderID, &review.CardTitle, &review.Reviewer, &review.Seller,
+		&review.Rating, &review.Text, &review.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetReviews получает отзывы о продавце, начиная с самых новых, и его среднюю оценку
+func (repo *ReviewsDBRepository) GetReviews(seller string, limit, offset int) (*SellerReviews, int, error) {
+	var sellerID string
+	err := repo.dtb.QueryRow("SELECT id FROM users WHERE username = $1;", seller).Scan(&sellerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("пользователь не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение пользователя: %v", err)
+	}
+
+	result := &SellerReviews{Items: []Review{}}
+	query := `SELECT ROUND(AVG(rating), 2), COUNT(*) FROM reviews WHERE seller_id = $1;`
+	err = repo.dtb.QueryRow(query, sellerID).Scan(&result.Rating, &result.Count)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение средней оценки: %v", err)
+	}
+
+	query = selectReviews + " WHERE r.seller_id = $1 ORDER BY r.created_at DESC, r.id DESC LIMIT $2 OFFSET $3;"
+	rows, err := repo.dtb.Query(query, sellerID, limit, offset)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение отзывов: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, hdr.InternalServerErrorCode, err
+		}
+		result.Items = append(result.Items, *review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, hdr.InternalServerErrorCode, err
+	}
+	return result, hdr.OKCode, nil
+}
@@ -0,0 +1,13 @@
+package reviews
+
+import (
+	"database/sql"
+)
+
+type ReviewsDBRepository struct {
+	dtb *sql.DB
+}
+
+func NewDBRepo(sdb *sql.DB) *ReviewsDBRepository {
+	return &ReviewsDBRepository{dtb: sdb}
+}
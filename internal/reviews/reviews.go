@@ -0,0 +1,40 @@
+package reviews
+
+import "time"
+
+// Review — отзыв покупателя о продавце после завершенной сделки
+type Review struct {
+	// ID — идентификатор отзыва
+	ID string `json:"id"`
+	// OrderID — завершенный заказ, по которому оставлен отзыв
+	OrderID string `json:"order_id"`
+	// CardTitle — заголовок объявления заказа
+	CardTitle string `json:"card_title"`
+	// Reviewer — логин автора отзыва
+	Reviewer string `json:"reviewer"`
+	// Seller — логин продавца
+	Seller string `json:"seller"`
+	// Rating — оценка от 1 до 5
+	Rating int `json:"rating"`
+	// Text — текст отзыва
+	Text string `json:"text"`
+	// CreatedAt — дата создания
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SellerReviews — отзывы о продавце со средней оценкой
+type SellerReviews struct {
+	// Rating — средняя оценка, null если отзывов нет
+	Rating *float64 `json:"rating"`
+	// Count — общее количество отзывов
+	Count int `json:"count"`
+	// Items — отзывы, начиная с самых новых
+	Items []Review `json:"items"`
+}
+
+type ReviewsRepo interface {
+	// AddReview добавляет отзыв покупателя о продавце по завершенному заказу без отзыва
+	AddReview(seller, reviewerID string, rating int, text string) (*Review, int, error)
+	// GetReviews получает отзывы о продавце и его среднюю оценку
+	GetReviews(seller string, limit, offset int) (*SellerReviews, int, error)
+}
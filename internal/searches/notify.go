@@ -0,0 +1,57 @@
+package searches
+
+import (
+	"fmt"
+	"marketplace/internal/cards"
+	"marketplace/internal/notifications"
+)
+
+// NotifyMatches создает уведомления для владельцев сохраненных поисков, которым удовлетворяет новое объявление.
+// Все поиски проверяются одним запросом: условия повторяют фильтры ленты объявлений.
+// Поиски автора объявления не проверяются. Возвращает количество созданных уведомлений
+func (repo *SearchesDBRepository) NotifyMatches(cardID, authorID string) (int, error) {
+	query := fmt.Sprintf(`
+        WITH RECURSIVE card AS (
+            SELECT
+                c.id,
+                c.price,
+                c.category_id,
+                c.search_vector,
+                u.username,
+                ARRAY(
+                    SELECT t.name FROM card_tags ct JOIN tags t ON t.id = ct.tag_id WHERE ct.card_id = c.id
+                ) AS tags
+            FROM cards c
+            JOIN users u ON u.id = c.user_id
+            WHERE c.id = $1 AND c.status = '%s' AND c.expires_at > NOW()
+        ), ancestors AS (
+            SELECT category_id AS id FROM card
+            UNION ALL
+            SELECT ct.parent_id FROM categories ct JOIN ancestors an ON ct.id = an.id WHERE ct.parent_id IS NOT NULL
+        )
+        INSERT INTO notifications (user_id, kind, card_id, search_id)
+        SELECT s.user_id, $3, card.id, s.id
+        FROM saved_searches s, card
+        WHERE s.user_id <> $2
+            AND (NOT s.filter ? 'price_min' OR card.price >= (s.filter->>'price_min')::numeric)
+            AND (NOT s.filter ? 'price_max' OR card.price <= (s.filter->>'price_max')::numeric)
+            AND (NOT s.filter ? 'author' OR card.username = s.filter->>'author')
+            AND (NOT s.filter ? 'category' OR (s.filter->>'category')::int IN (SELECT id FROM ancestors))
+            AND (NOT s.filter ? 'tags' OR ARRAY(SELECT jsonb_array_elements_text(s.filter->'tags')) <@ card.tags)
+            AND (NOT s.filter ? 'any_tags' OR ARRAY(SELECT jsonb_array_elements_text(s.filter->'any_tags')) && card.tags)
+            AND (NOT s.filter ? 'q' OR card.search_vector @@ (
+                websearch_to_tsquery('russian', s.filter->>'q') || websearch_to_tsquery('english', s.filter->>'q')
+            ))
+        ON CONFLICT (search_id, card_id) WHERE search_id IS NOT NULL DO NOTHING;`, cards.StatusPublished)
+
+	result, err := repo.dtb.Exec(query, cardID, authorID, notifications.KindSearchMatch)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса к базе данных: создание уведомлений: %v", err)
+	}
+
+	created, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error while getting the number of created notifications: %v", err)
+	}
+	return int(created), nil
+}
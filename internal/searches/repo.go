@@ -0,0 +1,13 @@
+package searches
+
+import (
+	"database/sql"
+)
+
+type SearchesDBRepository struct {
+	dtb *sql.DB
+}
+
+func NewDBRepo(sdb *sql.DB) *SearchesDBRepository {
+	return &SearchesDBRepository{dtb: sdb}
+}
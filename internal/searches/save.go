@@ -0,0 +1,73 @@
+package searches
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// SaveSearch сохраняет поиск пользователя
+func (repo *SearchesDBRepository) SaveSearch(userID string, srch *SavedSearch) (*SavedSearch, error) {
+	filter, err := json.Marshal(srch.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("error while serializing the search filter: %v", err)
+	}
+
+	query := `INSERT INTO saved_searches (user_id, name, filter) VALUES ($1, $2, $3) RETURNING id, created_at;`
+	err = repo.dtb.QueryRow(query, userID, srch.Name, filter).Scan(&srch.ID, &srch.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: сохранение поиска: %v", err)
+	}
+	return srch, nil
+}
+
+// GetSearches получает сохраненные поиски пользователя
+func (repo *SearchesDBRepository) GetSearches(userID string) ([]SavedSearch, error) {
+	query := `SELECT id, name, filter, created_at FROM saved_searches WHERE user_id = $1 ORDER BY created_at DESC;`
+	rows, err := repo.dtb.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение сохраненных поисков: %v", err)
+	}
+	defer rows.Close()
+
+	searches := []SavedSearch{}
+	for rows.Next() {
+		var srch SavedSearch
+		var filter []byte
+		if err := rows.Scan(&srch.ID, &srch.Name, &filter, &srch.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(filter, &srch.Filter); err != nil {
+			return nil, fmt.Errorf("error while deserializing the search filter: %v", err)
+		}
+		searches = append(searches, srch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return searches, nil
+}
+
+// DeleteSearch удаляет сохраненный поиск пользователя
+func (repo *SearchesDBRepository) DeleteSearch(userID, searchID string) (int, error) {
+	var ownerID string
+	err := repo.dtb.QueryRow("SELECT user_id FROM saved_searches WHERE id = $1;", searchID).Scan(&ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return hdr.NotFoundCode, fmt.Errorf("сохраненный поиск не существует")
+	}
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение сохраненного поиска: %v", err)
+	}
+
+	if ownerID != userID {
+		return hdr.ForbiddenCode, fmt.Errorf("сохраненный поиск принадлежит другому пользователю")
+	}
+
+	_, err = repo.dtb.Exec("DELETE FROM saved_searches WHERE id = $1;", searchID)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: удаление сохраненного поиска: %v", err)
+	}
+	return hdr.OKCode, nil
+}
@@ -0,0 +1,28 @@
+package searches
+
+import (
+	"marketplace/internal/cards"
+	"time"
+)
+
+type SavedSearch struct {
+	// ID — идентификатор сохраненного поиска
+	ID string `json:"id"`
+	// Name — название сохраненного поиска
+	Name string `json:"name"`
+	// Filter — фильтры ленты объявлений
+	Filter cards.QueryParams `json:"filter"`
+	// CreatedAt — дата создания
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchesRepo interface {
+	// SaveSearch сохраняет поиск пользователя
+	SaveSearch(userID string, srch *SavedSearch) (*SavedSearch, error)
+	// GetSearches получает сохраненные поиски пользователя
+	GetSearches(userID string) ([]SavedSearch, error)
+	// DeleteSearch удаляет сохраненный поиск пользователя
+	DeleteSearch(userID, searchID string) (int, error)
+	// NotifyMatches создает уведомления для владельцев сохраненных поисков, которым удовлетворяет новое объявление
+	NotifyMatches(cardID, authorID string) (int, error)
+}
@@ -0,0 +1,63 @@
+package sessions
+
+import (
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// GetSessions получает активные сессии пользователя, начиная с последних использованных
+func (repo *SessionsDBRepository) GetSessions(userID, currentSessionID string) ([]Session, int, error) {
+	query := `
+    SELECT id, user_agent, ip, created_at, last_used_at, id = $2
+    FROM sessions
+    WHERE user_id = $1 AND revoked_at IS NULL
+    ORDER BY last_used_at DESC, id;`
+	rows, err := repo.dtb.Query(query, userID, currentSessionID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение сессий: %v", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		err := rows.Scan(&session.ID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastUsedAt, &session.IsCurrent)
+		if err != nil {
+			return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение сессий: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение сессий: %v", err)
+	}
+	return sessions, hdr.OKCode, nil
+}
+
+// RevokeSession завершает сессию пользователя. Повторное завершение сессии не является ошибкой.
+// Сессия другого пользователя считается несуществующей, чтобы не раскрывать чужие идентификаторы сессий
+func (repo *SessionsDBRepository) RevokeSession(sessionID, userID string) (int, error) {
+	query := "UPDATE sessions SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP) WHERE id = $1 AND user_id = $2;"
+	res, err := repo.dtb.Exec(query, sessionID, userID)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: завершение сессии: %v", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("error while getting the number of revoked sessions: %v", err)
+	}
+	if count == 0 {
+		return hdr.NotFoundCode, fmt.Errorf("сессия не существует")
+	}
+	return hdr.OKCode, nil
+}
+
+// RevokeAllSessions завершает все сессии пользователя
+func (repo *SessionsDBRepository) RevokeAllSessions(userID string) (int, error) {
+	query := "UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL;"
+	_, err := repo.dtb.Exec(query, userID)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: завершение сессий: %v", err)
+	}
+	return hdr.OKCode, nil
+}
@@ -0,0 +1,155 @@
+package sessions
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// insertRefreshToken выдает новый refresh-токен в рамках сессии
+func (repo *SessionsDBRepository) insertRefreshToken(tx *sql.Tx, sessionID string) (string, error) {
+	refreshToken, tokenHash, err := generateRefreshToken()
+	if err != nil {
+		return "", err
+	}
+
+	query := `INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+              VALUES ($1, $2, CURRENT_TIMESTAMP + make_interval(days => $3));`
+	_, err = tx.Exec(query, sessionID, tokenHash, repo.ttlDays)
+	if err != nil {
+		return "", fmt.Errorf("ошибка запроса к базе данных: выдача refresh-токена: %v", err)
+	}
+	return refreshToken, nil
+}
+
+// CreateSession создает сессию пользователя и выдает первый refresh-токен семейства.
+// Возвращает идентификатор сессии и refresh-токен
+func (repo *SessionsDBRepository) CreateSession(userID, userAgent, ip string) (string, string, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return "", "", hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var sessionID string
+	query := "INSERT INTO sessions (user_id, user_agent, ip) VALUES ($1, $2, $3) RETURNING id;"
+	err = tx.QueryRow(query, userID, userAgent, ip).Scan(&sessionID)
+	if err != nil {
+		return "", "", hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: создание сессии: %v", err)
+	}
+
+	refreshToken, err := repo.insertRefreshToken(tx, sessionID)
+	if err != nil {
+		return "", "", hdr.InternalServerErrorCode, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", "", hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return sessionID, refreshToken, hdr.OKCode, nil
+}
+
+// RotateRefreshToken обменивает refresh-токен на новый. Повторное предъявление
+// уже обмененного токена означает его кражу, поэтому сессия отзывается целиком
+func (repo *SessionsDBRepository) RotateRefreshToken(refreshToken string) (*Rotation, int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var tokenID string
+	var isUsed, isExpired, isRevoked bool
+	rotation := &Rotation{}
+	query := `
+    SELECT rt.id, rt.used_at IS NOT NULL, rt.expires_at <= CURRENT_TIMESTAMP, s.revoked_at IS NOT NULL,
+           s.id, u.id, u.username
+    FROM refresh_tokens rt
+    JOIN sessions s ON s.id = rt.session_id
+    JOIN users u ON u.id = s.user_id
+    WHERE rt.token_hash = $1
+    FOR UPDATE OF rt, s;`
+	err = tx.QueryRow(query, hashRefreshToken(refreshToken)).Scan(&tokenID, &isUsed, &isExpired, &isRevoked,
+		&rotation.SessionID, &rotation.UserID, &rotation.Username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.UnauthorizedCode, fmt.Errorf("недействительный refresh-токен")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение refresh-токена: %v", err)
+	}
+
+	switch {
+	case isRevoked:
+		return nil, hdr.UnauthorizedCode, fmt.Errorf("сессия завершена")
+	case isUsed:
+		if err := revokeSession(tx, rotation.SessionID); err != nil {
+			return nil, hdr.InternalServerErrorCode, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+		}
+		return nil, hdr.UnauthorizedCode, fmt.Errorf("повторное использование refresh-токена: сессия завершена")
+	case isExpired:
+		return nil, hdr.UnauthorizedCode, fmt.Errorf("срок действия refresh-токена истек")
+	}
+
+	_, err = tx.Exec("UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1;", tokenID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: обмен refresh-токена: %v", err)
+	}
+
+	_, err = tx.Exec("UPDATE sessions SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1;", rotation.SessionID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: обновление сессии: %v", err)
+	}
+
+	rotation.RefreshToken, err = repo.insertRefreshToken(tx, rotation.SessionID)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return rotation, hdr.OKCode, nil
+}
+
+// RevokeByRefreshToken завершает сессию, которой принадлежит refresh-токен.
+// Повторное завершение сессии не является ошибкой
+func (repo *SessionsDBRepository) RevokeByRefreshToken(refreshToken string) (int, error) {
+	tx, err := repo.dtb.Begin()
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка начала транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var sessionID string
+	query := "SELECT session_id FROM refresh_tokens WHERE token_hash = $1;"
+	err = tx.QueryRow(query, hashRefreshToken(refreshToken)).Scan(&sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return hdr.UnauthorizedCode, fmt.Errorf("недействительный refresh-токен")
+	}
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение refresh-токена: %v", err)
+	}
+
+	if err := revokeSession(tx, sessionID); err != nil {
+		return hdr.InternalServerErrorCode, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+	}
+	return hdr.OKCode, nil
+}
+
+// revokeSession завершает сессию, если она еще не завершена
+func revokeSession(tx *sql.Tx, sessionID string) error {
+	query := "UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL;"
+	_, err := tx.Exec(query, sessionID)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к базе данных: завершение сессии: %v", err)
+	}
+	return nil
+}
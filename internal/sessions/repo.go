@@ -0,0 +1,36 @@
+package sessions
+
+import (
+	"database/sql"
+	"log"
+	"os"
+	"strconv"
+)
+
+// defaultTTLDays — срок действия refresh-токена в днях по умолчанию
+const defaultTTLDays int = 30
+
+type SessionsDBRepository struct {
+	dtb *sql.DB
+	// ttlDays — срок действия refresh-токена в днях
+	ttlDays int
+}
+
+func NewDBRepo(sdb *sql.DB) *SessionsDBRepository {
+	return &SessionsDBRepository{dtb: sdb, ttlDays: getTTLDays()}
+}
+
+// getTTLDays получает срок действия refresh-токена из переменной окружения REFRESH_TOKEN_TTL_DAYS
+func getTTLDays() int {
+	ttlParam := os.Getenv("REFRESH_TOKEN_TTL_DAYS")
+	if ttlParam == "" {
+		return defaultTTLDays
+	}
+
+	ttl, err := strconv.Atoi(ttlParam)
+	if err != nil || ttl <= 0 {
+		log.Printf("invalid REFRESH_TOKEN_TTL_DAYS value %q, using the default value %d\n", ttlParam, defaultTTLDays)
+		return defaultTTLDays
+	}
+	return ttl
+}
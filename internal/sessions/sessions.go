@@ -0,0 +1,50 @@
+package sessions
+
+import "time"
+
+// Session — активная сессия пользователя
+type Session struct {
+	// ID — идентификатор сессии
+	ID string `json:"id"`
+	// UserAgent — устройство, с которого выполнен вход
+	UserAgent string `json:"user_agent"`
+	// IP — IP-адрес, с которого выполнен вход
+	IP string `json:"ip"`
+	// CreatedAt — дата входа
+	CreatedAt time.Time `json:"created_at"`
+	// LastUsedAt — дата последнего использования
+	LastUsedAt time.Time `json:"last_used_at"`
+	// IsCurrent — признак сессии, в рамках которой выполнен запрос
+	IsCurrent bool `json:"is_current"`
+}
+
+// Rotation — результат обмена refresh-токена на новый
+type Rotation struct {
+	// UserID — идентификатор владельца сессии
+	UserID string
+	// Username — логин владельца сессии
+	Username string
+	// SessionID — идентификатор сессии
+	SessionID string
+	// RefreshToken — новый refresh-токен
+	RefreshToken string
+}
+
+type SessionsRepo interface {
+	// CreateSession создает сессию пользователя и выдает первый refresh-токен семейства.
+	// Возвращает идентификатор сессии и refresh-токен
+	CreateSession(userID, userAgent, ip string) (string, string, int, error)
+	// RotateRefreshToken обменивает refresh-токен на новый. Повторное предъявление
+	// уже обмененного токена отзывает всю сессию
+	RotateRefreshToken(refreshToken string) (*Rotation, int, error)
+	// RevokeByRefreshToken завершает сессию, которой принадлежит refresh-токен
+	RevokeByRefreshToken(refreshToken string) (int, error)
+	// GetSessions получает активные сессии пользователя
+	GetSessions(userID, currentSessionID string) ([]Session, int, error)
+	// RevokeSession завершает сессию пользователя
+	RevokeSession(sessionID, userID string) (int, error)
+	// RevokeAllSessions завершает все сессии пользователя
+	RevokeAllSessions(userID string) (int, error)
+	// TouchSession проверяет, что сессия принадлежит пользователю и не завершена, и отмечает ее использование
+	TouchSession(sessionID, userID string) (bool, error)
+}
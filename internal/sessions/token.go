@@ -0,0 +1,30 @@
+package sessions
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// refreshTokenLen — длина refresh-токена в байтах
+const refreshTokenLen int = 32
+
+// generateRefreshToken создает случайный refresh-токен и его хэш для хранения в базе данных
+func generateRefreshToken() (string, string, error) {
+	raw := make([]byte, refreshTokenLen)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("ошибка генерации refresh-токена: %v", err)
+	}
+
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+	return refreshToken, hashRefreshToken(refreshToken), nil
+}
+
+// hashRefreshToken хэширует refresh-токен. Токен случаен и достаточно длинный,
+// поэтому медленный алгоритм хэширования паролей не нужен
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
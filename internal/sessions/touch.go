@@ -0,0 +1,26 @@
+package sessions
+
+import "fmt"
+
+// TouchSession проверяет, что сессия принадлежит пользователю и не завершена.
+// Дата последнего использования обновляется не чаще раза в минуту, чтобы не записывать ее при каждом запросе
+func (repo *SessionsDBRepository) TouchSession(sessionID, userID string) (bool, error) {
+	query := `
+        WITH active AS (
+            SELECT id, last_used_at FROM sessions
+            WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+        ), touched AS (
+            UPDATE sessions s SET last_used_at = CURRENT_TIMESTAMP
+            FROM active a
+            WHERE s.id = a.id AND a.last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute'
+            RETURNING s.id
+        )
+        SELECT EXISTS(SELECT 1 FROM active);`
+
+	var active bool
+	err := repo.dtb.QueryRow(query, sessionID, userID).Scan(&active)
+	if err != nil {
+		return false, fmt.Errorf("ошибка запроса к базе данных: проверка сессии: %v", err)
+	}
+	return active, nil
+}
@@ -0,0 +1,39 @@
+package tags
+
+import (
+	"fmt"
+	"marketplace/internal/cards"
+)
+
+// GetPopular получает самые популярные теги среди опубликованных объявлений
+func (repo *TagsDBRepository) GetPopular(limit int) ([]PopularTag, error) {
+	query := `
+        SELECT t.name, COUNT(*) AS cnt
+        FROM tags t
+        JOIN card_tags ct ON ct.tag_id = t.id
+        JOIN cards c ON c.id = ct.card_id
+        WHERE c.status = $2 AND c.expires_at > NOW()
+        GROUP BY t.name
+        ORDER BY cnt DESC, t.name
+        LIMIT $1;
+    `
+
+	rows, err := repo.dtb.Query(query, limit, cards.StatusPublished)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса к базе данных: получение популярных тегов: %v", err)
+	}
+	defer rows.Close()
+
+	popular := []PopularTag{}
+	for rows.Next() {
+		var tag PopularTag
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, err
+		}
+		popular = append(popular, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return popular, nil
+}
@@ -0,0 +1,18 @@
+package tags
+
+import "strings"
+
+// Normalize приводит теги к нижнему регистру, удаляет пробелы по краям, пустые теги и повторы
+func Normalize(rawTags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+	for _, rawTag := range rawTags {
+		tag := strings.ToLower(strings.TrimSpace(rawTag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
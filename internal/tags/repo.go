@@ -0,0 +1,13 @@
+package tags
+
+import (
+	"database/sql"
+)
+
+type TagsDBRepository struct {
+	dtb *sql.DB
+}
+
+func NewDBRepo(sdb *sql.DB) *TagsDBRepository {
+	return &TagsDBRepository{dtb: sdb}
+}
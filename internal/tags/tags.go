@@ -0,0 +1,13 @@
+package tags
+
+type PopularTag struct {
+	// Name — название тега
+	Name string `json:"name"`
+	// Count — количество опубликованных объявлений с тегом
+	Count int `json:"count"`
+}
+
+type TagsRepo interface {
+	// GetPopular получает самые популярные теги
+	GetPopular(limit int) ([]PopularTag, error)
+}
@@ -0,0 +1,300 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits — минимальная длина ключа RSA
+const minRSABits int = 2048
+
+var (
+	// ErrUnknownKey — токен подписан ключом, которого нет среди ключей проверки
+	ErrUnknownKey = errors.New("токен подписан неизвестным ключом")
+	// ErrNoKeySet — ключи подписи и проверки токенов не установлены
+	ErrNoKeySet = errors.New("ключи подписи токенов не настроены")
+
+	// keySet — ключи, которыми подписываются и проверяются токены
+	keySet *KeySet
+	// keySetMutex защищает keySet
+	keySetMutex sync.Mutex
+)
+
+// key — ключ подписи или проверки токенов
+type key struct {
+	// id — идентификатор ключа (заголовок kid), отпечаток открытого ключа по RFC 7638
+	id string
+	// method — алгоритм подписи
+	method jwt.SigningMethod
+	// private — закрытый ключ, nil для ключей, используемых только для проверки
+	private crypto.PrivateKey
+	// public — открытый ключ
+	public crypto.PublicKey
+}
+
+// KeySet — ключ подписи токенов и ключи их проверки. Во время смены ключа
+// проверка выполняется и новым, и прежними ключами
+type KeySet struct {
+	signing *key
+	keys    map[string]*key
+}
+
+// JWK — открытый ключ в формате JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// N и E — модуль и экспонента ключа RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Crv и X — кривая и открытый ключ Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet — набор открытых ключей в формате JWK Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// LoadKeySet загружает ключи из переменных окружения:
+// JWT_PRIVATE_KEY_FILE или JWT_PRIVATE_KEY — закрытый ключ подписи в формате PEM (RSA или Ed25519),
+// JWT_VERIFICATION_KEY_FILES — пути к прежним ключам через запятую, которыми токены только проверяются.
+// Если ключ подписи не задан, возвращает nil
+func LoadKeySet() (*KeySet, error) {
+	var signingPEM []byte
+	if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения ключа подписи: %v", err)
+		}
+		signingPEM = data
+	} else if inline := os.Getenv("JWT_PRIVATE_KEY"); inline != "" {
+		signingPEM = []byte(inline)
+	}
+	if signingPEM == nil {
+		return nil, nil
+	}
+
+	var verificationPEMs [][]byte
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения ключа проверки: %v", err)
+		}
+		verificationPEMs = append(verificationPEMs, data)
+	}
+
+	return NewKeySet(signingPEM, verificationPEMs...)
+}
+
+// NewKeySet создает набор ключей из закрытого ключа подписи и ключей проверки в формате PEM.
+// Ключами проверки могут быть как открытые, так и закрытые ключи
+func NewKeySet(signingPEM []byte, verificationPEMs ...[]byte) (*KeySet, error) {
+	signing, err := parseKey(signingPEM)
+	if err != nil {
+		return nil, err
+	}
+	if signing.private == nil {
+		return nil, fmt.Errorf("ключ подписи должен быть закрытым ключом")
+	}
+
+	ks := &KeySet{signing: signing, keys: map[string]*key{signing.id: signing}}
+	for _, data := range verificationPEMs {
+		verification, err := parseKey(data)
+		if err != nil {
+			return nil, err
+		}
+		verification.private = nil
+		if _, ok := ks.keys[verification.id]; !ok {
+			ks.keys[verification.id] = verification
+		}
+	}
+	return ks, nil
+}
+
+// GenerateKeySet создает набор из одного случайного ключа Ed25519 для тестов.
+// Токены, подписанные таким ключом, перестают проходить проверку после перезапуска
+func GenerateKeySet() (*KeySet, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации ключа подписи: %v", err)
+	}
+
+	signing, err := newKey(private, public)
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{signing: signing, keys: map[string]*key{signing.id: signing}}, nil
+}
+
+// SetKeySet устанавливает ключи, которыми подписываются и проверяются токены
+func SetKeySet(ks *KeySet) {
+	keySetMutex.Lock()
+	defer keySetMutex.Unlock()
+	keySet = ks
+}
+
+// getKeySet получает установленные ключи
+func getKeySet() (*KeySet, error) {
+	keySetMutex.Lock()
+	defer keySetMutex.Unlock()
+
+	if keySet == nil {
+		return nil, ErrNoKeySet
+	}
+	return keySet, nil
+}
+
+// Sign подписывает утверждения ключом подписи и указывает его идентификатор в заголовке kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.id
+	return token.SignedString(ks.signing.private)
+}
+
+// keyFunc выбирает ключ проверки по заголовку kid и проверяет, что токен подписан алгоритмом этого ключа
+func (ks *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	verification, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != verification.method.Alg() {
+		return nil, fmt.Errorf("expected another signing method")
+	}
+	return verification.public, nil
+}
+
+// JWKS получает открытые ключи проверки токенов в формате JWK Set
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{jwkOf(ks.signing)}}
+	for id, verification := range ks.keys {
+		if id != ks.signing.id {
+			set.Keys = append(set.Keys, jwkOf(verification))
+		}
+	}
+	return set
+}
+
+// GetJWKS получает открытые ключи проверки токенов в формате JWK Set
+func GetJWKS() (JWKSet, error) {
+	ks, err := getKeySet()
+	if err != nil {
+		return JWKSet{}, err
+	}
+	return ks.JWKS(), nil
+}
+
+// parseKey разбирает ключ RSA или Ed25519 в формате PEM: закрытый ключ PKCS #8 или PKCS #1
+// либо открытый ключ PKIX
+func parseKey(data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("ключ должен быть в формате PEM")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора закрытого ключа: %v", err)
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("неподдерживаемый тип ключа")
+		}
+		return newKey(private, signer.Public())
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора закрытого ключа: %v", err)
+		}
+		return newKey(private, private.Public())
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора открытого ключа: %v", err)
+		}
+		return newKey(nil, public)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип блока PEM: %s", block.Type)
+	}
+}
+
+// newKey определяет алгоритм подписи по типу ключа и вычисляет идентификатор ключа
+func newKey(private crypto.PrivateKey, public crypto.PublicKey) (*key, error) {
+	k := &key{private: private, public: public}
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("длина ключа RSA должна быть не меньше %d бит", minRSABits)
+		}
+		k.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип ключа: поддерживаются только RSA и Ed25519")
+	}
+
+	k.id = thumbprint(jwkOf(k))
+	return k, nil
+}
+
+// jwkOf представляет открытый ключ в формате JWK
+func jwkOf(k *key) JWK {
+	jwk := JWK{Kid: k.id, Use: "sig", Alg: k.method.Alg()}
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+// thumbprint вычисляет отпечаток ключа JWK по RFC 7638
+func thumbprint(jwk JWK) string {
+	var members any
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,170 @@
+package token_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"marketplace/internal/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// privatePEM кодирует закрытый ключ в формат PEM PKCS #8
+func privatePEM(t *testing.T, private crypto.PrivateKey) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("Ошибка кодирования закрытого ключа: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// publicPEM кодирует открытый ключ в формат PEM PKIX
+func publicPEM(t *testing.T, public crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("Ошибка кодирования открытого ключа: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// newKeySet создает набор ключей и завершает тест при ошибке
+func newKeySet(t *testing.T, signingPEM []byte, verificationPEMs ...[]byte) *token.KeySet {
+	ks, err := token.NewKeySet(signingPEM, verificationPEMs...)
+	if err != nil {
+		t.Fatalf("Ошибка создания набора ключей: %v", err)
+	}
+	return ks
+}
+
+// TestKeyRotation тестирует подпись токенов ключами RS256 и EdDSA и их проверку во время смены ключа
+func TestKeyRotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа RSA: %v", err)
+	}
+	_, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа Ed25519: %v", err)
+	}
+	t.Cleanup(func() { token.SetKeySet(nil) })
+
+	oldKeys := newKeySet(t, privatePEM(t, rsaKey))
+	token.SetKeySet(oldKeys)
+	oldToken, err := token.CreateJWTtoken(uuid.NewString(), "user1", uuid.NewString())
+	if err != nil {
+		t.Fatalf("Ошибка создания токена: %v", err)
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(oldToken, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("Ошибка разбора токена: %v", err)
+	}
+	oldKID := oldKeys.JWKS().Keys[0].Kid
+	if parsed.Method.Alg() != "RS256" || parsed.Header["kid"] != oldKID {
+		t.Errorf("Ожидался заголовок alg=RS256, kid=%s, но получено: %v", oldKID, parsed.Header)
+	}
+
+	newKeys := newKeySet(t, privatePEM(t, edPrivate), publicPEM(t, &rsaKey.PublicKey))
+	token.SetKeySet(newKeys)
+	newToken, err := token.CreateJWTtoken(uuid.NewString(), "user3", uuid.NewString())
+	if err != nil {
+		t.Fatalf("Ошибка создания токена: %v", err)
+	}
+
+	for tokenString, username := range map[string]string{oldToken: "user1", newToken: "user3"} {
+		claims, err := token.ParseToken(tokenString)
+		if err != nil || claims.Username != username {
+			t.Errorf("Ожидался логин %s, но получено: %+v, %v", username, claims, err)
+		}
+	}
+
+	jwks := newKeys.JWKS()
+	if len(jwks.Keys) != 2 || jwks.Keys[0].Alg != "EdDSA" || jwks.Keys[0].Crv != "Ed25519" || jwks.Keys[0].X == "" {
+		t.Fatalf("Ожидался ключ подписи Ed25519 первым в наборе, но получено: %+v", jwks)
+	}
+	if jwks.Keys[1].Kid != oldKID || jwks.Keys[1].Alg != "RS256" || jwks.Keys[1].N == "" || jwks.Keys[1].E != "AQAB" {
+		t.Errorf("Ожидался прежний ключ RSA в наборе, но получено: %+v", jwks.Keys[1])
+	}
+
+	t.Run("ключ выведен из обращения", func(t *testing.T) {
+		token.SetKeySet(newKeySet(t, privatePEM(t, edPrivate)))
+		if _, err := token.ParseToken(oldToken); !errors.Is(err, token.ErrUnknownKey) {
+			t.Error("Ожидалась ошибка проверки токена, подписанного удаленным ключом")
+		}
+	})
+}
+
+// TestNewKeySetErrors тестирует отклонение неподходящих ключей
+func TestNewKeySetErrors(t *testing.T) {
+	weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа RSA: %v", err)
+	}
+	edPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа Ed25519: %v", err)
+	}
+
+	tests := map[string][]byte{
+		"короткий ключ RSA":         privatePEM(t, weakKey),
+		"открытый ключ для подписи": publicPEM(t, edPublic),
+		"не PEM": []byte("ExampleTokenSecret"),
+	}
+	for name, data := range tests {
+		if _, err := token.NewKeySet(data); err == nil {
+			t.Errorf("%s: ожидалась ошибка", name)
+		}
+	}
+}
+
+// TestLoadKeySet тестирует загрузку ключей из файлов, указанных в переменных окружения
+func TestLoadKeySet(t *testing.T) {
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+	t.Setenv("JWT_PRIVATE_KEY", "")
+	ks, err := token.LoadKeySet()
+	if err != nil || ks != nil {
+		t.Fatalf("Без настроенного ключа ожидался nil, но получено: %v, %v", ks, err)
+	}
+
+	token.SetKeySet(nil)
+	if _, err := token.CreateJWTtoken(uuid.NewString(), "user1", uuid.NewString()); !errors.Is(err, token.ErrNoKeySet) {
+		t.Errorf("Без ключей ожидалась ошибка %v, но получено: %v", token.ErrNoKeySet, err)
+	}
+
+	_, signing, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа Ed25519: %v", err)
+	}
+	oldPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Ошибка генерации ключа Ed25519: %v", err)
+	}
+
+	dir := t.TempDir()
+	signingPath := filepath.Join(dir, "signing.pem")
+	oldPath := filepath.Join(dir, "old.pem")
+	if err := os.WriteFile(signingPath, privatePEM(t, signing), 0o600); err != nil {
+		t.Fatalf("Ошибка записи ключа: %v", err)
+	}
+	if err := os.WriteFile(oldPath, publicPEM(t, oldPublic), 0o600); err != nil {
+		t.Fatalf("Ошибка записи ключа: %v", err)
+	}
+
+	t.Setenv("JWT_PRIVATE_KEY_FILE", signingPath)
+	t.Setenv("JWT_VERIFICATION_KEY_FILES", oldPath+", ")
+	ks, err = token.LoadKeySet()
+	if err != nil {
+		t.Fatalf("Ошибка загрузки ключей: %v", err)
+	}
+	if keys := ks.JWKS().Keys; len(keys) != 2 {
+		t.Errorf("Ожидалось 2 ключа проверки, но получено: %+v", keys)
+	}
+}
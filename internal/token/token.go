@@ -1,70 +1,186 @@
 package token
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
-	"marketplace/internal/utils"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// AccessTokenTTL — срок действия access-токена
+const AccessTokenTTL = 1300 * time.Second
+
+const (
+	// defaultIssuer — издатель токенов (утверждение iss) по умолчанию
+	defaultIssuer string = "marketplace"
+	// defaultAudience — получатель токенов (утверждение aud) по умолчанию
+	defaultAudience string = "marketplace"
+	// defaultLeeway — допустимое расхождение часов при проверке сроков действия токена по умолчанию
+	defaultLeeway = 30 * time.Second
+)
+
+// Ошибки проверки токена
 var (
-	ExampleTokenSecret = []byte("ExampleTokenSecret")
-	ErrNoToken         = errors.New("no token was in the request")
+	ErrNoToken        = errors.New("no token was in the request")
+	ErrInvalidToken   = errors.New("недействительный токен")
+	ErrExpiredToken   = errors.New("срок действия токена истек")
+	ErrInvalidClaims  = errors.New("токен не содержит обязательных утверждений")
+	ErrSessionRevoked = errors.New("сессия завершена")
 )
 
-func CreateJWTtoken(username string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user": map[string]interface{}{
-			"username": username,
-		},
-		"iat": time.Now().Unix(),
-		"exp": time.Now().Unix() + 1300,
+// Claims — утверждения access-токена. В утверждении sub передается идентификатор пользователя
+type Claims struct {
+	jwt.RegisteredClaims
+	// Username — логин пользователя
+	Username string `json:"username"`
+	// SessionID — идентификатор сессии, в рамках которой выдан токен
+	SessionID string `json:"sid"`
+}
+
+// settings — издатель и получатель токенов и допустимое расхождение часов
+type settings struct {
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+var (
+	// loadedSettings — настройки, прочитанные из переменных окружения
+	loadedSettings settings
+	// settingsOnce обеспечивает однократное чтение настроек
+	settingsOnce sync.Once
+)
+
+// getSettings получает настройки токенов из переменных окружения JWT_ISSUER, JWT_AUDIENCE и JWT_LEEWAY_SECONDS
+func getSettings() settings {
+	settingsOnce.Do(func() {
+		loadedSettings = settings{issuer: defaultIssuer, audience: defaultAudience, leeway: defaultLeeway}
+		if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+			loadedSettings.issuer = issuer
+		}
+		if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+			loadedSettings.audience = audience
+		}
+		if leewayParam := os.Getenv("JWT_LEEWAY_SECONDS"); leewayParam != "" {
+			leeway, err := strconv.Atoi(leewayParam)
+			if err != nil || leeway < 0 {
+				log.Printf("invalid JWT_LEEWAY_SECONDS value %q, using the default value %v\n", leewayParam, defaultLeeway)
+			} else {
+				loadedSettings.leeway = time.Duration(leeway) * time.Second
+			}
+		}
 	})
-	tokenString, err := token.SignedString(ExampleTokenSecret)
-	return tokenString, err
+	return loadedSettings
 }
 
-func Check(rqt *http.Request, dtb *sql.DB) (bool, error) {
-	username, err := GetPayload(rqt)
+// CreateJWTtoken создает access-токен пользователя userID, выданный в рамках сессии sessionID
+func CreateJWTtoken(userID, username, sessionID string) (string, error) {
+	ks, err := getKeySet()
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
-	exists, err := utils.CheckUser(dtb, username)
+	cfg := getSettings()
+	now := time.Now()
+	return ks.Sign(Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{cfg.audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+		Username:  username,
+		SessionID: sessionID,
+	})
+}
+
+// ParseToken проверяет подпись, издателя, получателя и сроки действия токена и получает его утверждения
+func ParseToken(tokenString string) (*Claims, error) {
+	ks, err := getKeySet()
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	cfg := getSettings()
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(cfg.issuer),
+		jwt.WithAudience(cfg.audience),
+		jwt.WithLeeway(cfg.leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+
+	var claims Claims
+	_, err = parser.ParseWithClaims(tokenString, &claims, ks.keyFunc)
+	switch {
+	case errors.Is(err, ErrUnknownKey):
+		return nil, ErrUnknownKey
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return nil, ErrExpiredToken
+	case err != nil:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
-	return exists, nil
+	if _, err := uuid.Parse(claims.Subject); err != nil || claims.Username == "" || claims.ID == "" {
+		return nil, ErrInvalidClaims
+	}
+	if _, err := uuid.Parse(claims.SessionID); err != nil {
+		return nil, ErrInvalidClaims
+	}
+	return &claims, nil
 }
 
-func GetPayload(rqt *http.Request) (string, error) {
-	inToken := rqt.Header.Get("Authorization")
+// GetClaims получает утверждения токена из заголовка Authorization, допуская префикс Bearer
+func GetClaims(rqt *http.Request) (*Claims, error) {
+	inToken := strings.TrimSpace(strings.TrimPrefix(rqt.Header.Get("Authorization"), "Bearer "))
 	if inToken == "" {
-		return "", ErrNoToken
+		return nil, ErrNoToken
 	}
+	return ParseToken(inToken)
+}
 
-	hashSecretGetter := func(token *jwt.Token) (any, error) {
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
-		if !ok {
-			return nil, fmt.Errorf("expected another signing method")
-		}
-		return ExampleTokenSecret, nil
+// SessionChecker проверяет сессию, в рамках которой выдан токен
+type SessionChecker interface {
+	// TouchSession проверяет, что сессия принадлежит пользователю и не завершена, и отмечает ее использование
+	TouchSession(sessionID, userID string) (bool, error)
+}
+
+// Check проверяет токен из запроса и то, что сессия, в рамках которой он выдан, не завершена
+func Check(rqt *http.Request, checker SessionChecker) (*Claims, error) {
+	claims, err := GetClaims(rqt)
+	if err != nil {
+		return nil, err
 	}
 
-	token, errJwt := jwt.Parse(inToken, hashSecretGetter)
-	if errJwt != nil {
-		return "", errJwt
+	active, err := checker.TouchSession(claims.SessionID, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, ErrSessionRevoked
 	}
+	return claims, nil
+}
 
-	payload, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return "", fmt.Errorf("error while fetching the payload")
+// IsUnauthorized проверяет, что ошибка вызвана отсутствием или недействительностью токена,
+// а не внутренней ошибкой сервера
+func IsUnauthorized(err error) bool {
+	for _, target := range []error{ErrNoToken, ErrInvalidToken, ErrExpiredToken, ErrInvalidClaims, ErrSessionRevoked, ErrUnknownKey} {
+		if errors.Is(err, target) {
+			return true
+		}
 	}
-	claims := payload["user"].(map[string]any)
-	return claims["username"].(string), nil
+	return false
 }
@@ -0,0 +1,128 @@
+package token_test
+
+import (
+	"errors"
+	"marketplace/internal/token"
+	"net/http"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// requestWithToken создает запрос с токеном в заголовке Authorization
+func requestWithToken(t *testing.T, tokenString string) *http.Request {
+	rqt, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	rqt.Header.Set("Authorization", tokenString)
+	return rqt
+}
+
+// validClaims создает утверждения, которые проходят проверку при настройках по умолчанию
+func validClaims() token.Claims {
+	now := time.Now()
+	return token.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "marketplace",
+			Subject:   uuid.NewString(),
+			Audience:  jwt.ClaimStrings{"marketplace"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+		Username:  "user1",
+		SessionID: uuid.NewString(),
+	}
+}
+
+// TestParseToken тестирует проверку утверждений токена
+func TestParseToken(t *testing.T) {
+	ks, err := token.GenerateKeySet()
+	if err != nil {
+		t.Fatalf("Ошибка создания набора ключей: %v", err)
+	}
+	token.SetKeySet(ks)
+	t.Cleanup(func() { token.SetKeySet(nil) })
+
+	userID, sessionID := uuid.NewString(), uuid.NewString()
+	issued, err := token.CreateJWTtoken(userID, "user1", sessionID)
+	if err != nil {
+		t.Fatalf("Ошибка создания токена: %v", err)
+	}
+
+	t.Run("выданный токен", func(t *testing.T) {
+		claims, err := token.GetClaims(requestWithToken(t, "Bearer "+issued))
+		if err != nil {
+			t.Fatalf("Ошибка проверки токена: %v", err)
+		}
+		if claims.Subject != userID || claims.Username != "user1" || claims.SessionID != sessionID ||
+			claims.ID == "" || claims.Issuer != "marketplace" {
+			t.Errorf("Получены неожиданные утверждения: %+v", claims)
+		}
+	})
+
+	t.Run("запрос без токена", func(t *testing.T) {
+		if _, err := token.GetClaims(requestWithToken(t, "")); !errors.Is(err, token.ErrNoToken) {
+			t.Errorf("Ожидалась ошибка %v, но получено: %v", token.ErrNoToken, err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		modify func(claims *token.Claims)
+		want   error
+	}{
+		{"другой издатель", func(c *token.Claims) { c.Issuer = "other" }, token.ErrInvalidToken},
+		{"другой получатель", func(c *token.Claims) { c.Audience = jwt.ClaimStrings{"other"} }, token.ErrInvalidToken},
+		{"без срока действия", func(c *token.Claims) { c.ExpiresAt = nil }, token.ErrInvalidToken},
+		{"срок действия истек", func(c *token.Claims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+		}, token.ErrExpiredToken},
+		{"срок действия истек в пределах допустимого расхождения часов", func(c *token.Claims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-5 * time.Second))
+		}, nil},
+		{"без sub", func(c *token.Claims) { c.Subject = "" }, token.ErrInvalidClaims},
+		{"sub не UUID", func(c *token.Claims) { c.Subject = "user1" }, token.ErrInvalidClaims},
+		{"без логина", func(c *token.Claims) { c.Username = "" }, token.ErrInvalidClaims},
+		{"без сессии", func(c *token.Claims) { c.SessionID = "" }, token.ErrInvalidClaims},
+		{"без jti", func(c *token.Claims) { c.ID = "" }, token.ErrInvalidClaims},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.modify(&claims)
+			signed, err := ks.Sign(claims)
+			if err != nil {
+				t.Fatalf("Ошибка подписи токена: %v", err)
+			}
+
+			_, err = token.ParseToken(signed)
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Ожидалась ошибка %v, но получено: %v", tt.want, err)
+			}
+			if tt.want != nil && !token.IsUnauthorized(err) {
+				t.Errorf("Ошибка %v должна приводить к ответу 401", err)
+			}
+		})
+	}
+
+	t.Run("подписанный токен с утверждениями неожиданного типа", func(t *testing.T) {
+		signed, err := ks.Sign(jwt.MapClaims{
+			"iss":  "marketplace",
+			"aud":  "marketplace",
+			"exp":  time.Now().Add(time.Minute).Unix(),
+			"user": "user1",
+			"sid":  42,
+		})
+		if err != nil {
+			t.Fatalf("Ошибка подписи токена: %v", err)
+		}
+
+		if _, err := token.ParseToken(signed); !token.IsUnauthorized(err) {
+			t.Errorf("Ожидалась ошибка проверки токена, но получено: %v", err)
+		}
+	})
+}
@@ -29,6 +29,17 @@ func (repo *UserDBRepository) SignIn(usr *User) (*User, int, error) {
 		return nil, hdr.UnauthorizedCode, fmt.Errorf("password is incorrect")
 	}
 
+	if NeedsRehash(passwordHash) {
+		newHash, err := HashPassword(usr.Password)
+		if err != nil {
+			return nil, hdr.InternalServerErrorCode, err
+		}
+		if err := SetPasswordHash(repo.dtb, usr.Username, newHash); err != nil {
+			return nil, hdr.InternalServerErrorCode, err
+		}
+		passwordHash = newHash
+	}
+
 	thisUser := User{Username: usr.Username, Password: passwordHash}
 	return &thisUser, hdr.OKCode, nil
 }
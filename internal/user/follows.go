@@ -0,0 +1,99 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	hdr "marketplace/internal/handlers"
+)
+
+// getIDByUsername получает идентификатор пользователя по логину
+func (repo *UserDBRepository) getIDByUsername(username string) (string, int, error) {
+	var userID string
+	err := repo.dtb.QueryRow("SELECT id FROM users WHERE username = $1;", username).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", hdr.NotFoundCode, fmt.Errorf("пользователь не существует")
+	}
+	if err != nil {
+		return "", hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение пользователя: %v", err)
+	}
+	return userID, hdr.OKCode, nil
+}
+
+// Follow подписывает пользователя на продавца. Повторная подписка не является ошибкой
+func (repo *UserDBRepository) Follow(followerID, seller string) (int, error) {
+	sellerID, code, err := repo.getIDByUsername(seller)
+	if err != nil {
+		return code, err
+	}
+	if sellerID == followerID {
+		return hdr.BadRequestCode, fmt.Errorf("ошибка: нельзя подписаться на себя")
+	}
+
+	query := `INSERT INTO follows (follower_id, seller_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`
+	_, err = repo.dtb.Exec(query, followerID, sellerID)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: подписка на продавца: %v", err)
+	}
+	return hdr.OKCode, nil
+}
+
+// Unfollow отменяет подписку пользователя на продавца
+func (repo *UserDBRepository) Unfollow(followerID, seller string) (int, error) {
+	sellerID, code, err := repo.getIDByUsername(seller)
+	if err != nil {
+		return code, err
+	}
+
+	_, err = repo.dtb.Exec("DELETE FROM follows WHERE follower_id = $1 AND seller_id = $2;", followerID, sellerID)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: отмена подписки на продавца: %v", err)
+	}
+	return hdr.OKCode, nil
+}
+
+// GetFollowers получает подписчиков пользователя, начиная с самых новых
+func (repo *UserDBRepository) GetFollowers(username string, limit, offset int) ([]Follow, int, error) {
+	return repo.queryFollows(username, "f.seller_id", "f.follower_id", limit, offset)
+}
+
+// GetFollowing получает продавцов, на которых подписан пользователь, начиная с самых новых подписок
+func (repo *UserDBRepository) GetFollowing(username string, limit, offset int) ([]Follow, int, error) {
+	return repo.queryFollows(username, "f.follower_id", "f.seller_id", limit, offset)
+}
+
+// queryFollows получает пользователей из столбца listColumn подписок, в которых
+// пользователь username указан в столбце ownerColumn
+func (repo *UserDBRepository) queryFollows(username, ownerColumn, listColumn string, limit, offset int) ([]Follow, int, error) {
+	userID, code, err := repo.getIDByUsername(username)
+	if err != nil {
+		return nil, code, err
+	}
+
+	query := fmt.Sprintf(`
+    SELECT u.username, COALESCE(u.display_name, u.username), f.created_at
+    FROM follows f
+    JOIN users u ON u.id = %s
+    WHERE %s = $1
+    ORDER BY f.created_at DESC, u.username
+    LIMIT $2 OFFSET $3;`, listColumn, ownerColumn)
+
+	rows, err := repo.dtb.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение подписок: %v", err)
+	}
+	defer rows.Close()
+
+	follows := []Follow{}
+	for rows.Next() {
+		var follow Follow
+		if err := rows.Scan(&follow.Username, &follow.DisplayName, &follow.FollowedAt); err != nil {
+			return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение подписок: %v", err)
+		}
+		follows = append(follows, follow)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение подписок: %v", err)
+	}
+	return follows, hdr.OKCode, nil
+}
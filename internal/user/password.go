@@ -1,12 +1,48 @@
 package user
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Параметры argon2id, с которыми хэшируются новые пароли
+const (
+	// argonTime — количество проходов по памяти
+	argonTime uint32 = 1
+	// argonMemory — объем памяти в КиБ
+	argonMemory uint32 = 64 * 1024
+	// argonThreads — степень параллелизма
+	argonThreads uint8 = 4
+	// argonKeyLen — длина хэша в байтах
+	argonKeyLen uint32 = 32
+	// argonSaltLen — длина соли в байтах
+	argonSaltLen int = 16
 )
 
+// argonPrefix — префикс хэша argon2id в формате PHC
+const argonPrefix string = "$argon2id$"
+
+// ErrInvalidHash — хэш пароля имеет неизвестный формат
+var ErrInvalidHash = errors.New("неизвестный формат хэша пароля")
+
+// argonHash — разобранный хэш argon2id в формате PHC
+type argonHash struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
 func GetPasswordHash(dtb *sql.DB, username string) (string, error) {
 	var passwordHash string
 	err := dtb.QueryRow("SELECT password_hash FROM users WHERE username = $1;", username).Scan(&passwordHash)
@@ -16,24 +52,88 @@ func GetPasswordHash(dtb *sql.DB, username string) (string, error) {
 	return passwordHash, nil
 }
 
-func HashPassword(password string) (string, error) {
-	salt := "|3%$cris2QJlfs|R"
-
-	hasher := sha256.New()
-	_, err := hasher.Write([]byte(password + salt))
+// SetPasswordHash заменяет хэш пароля пользователя
+func SetPasswordHash(dtb *sql.DB, username, passwordHash string) error {
+	_, err := dtb.Exec("UPDATE users SET password_hash = $1 WHERE username = $2;", passwordHash, username)
 	if err != nil {
-		return "", fmt.Errorf("error while hashing the password")
+		return fmt.Errorf("ошибка запроса к базе данных: обновление хэша пароля: %v", err)
+	}
+	return nil
+}
+
+// HashPassword хэширует пароль алгоритмом argon2id со случайной солью
+// и возвращает хэш в формате PHC: $argon2id$v=19$m=...,t=...,p=...$соль$хэш
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error while generating the salt: %v", err)
 	}
-	hashedBytes := hasher.Sum(nil)
 
-	hashedPassword := hex.EncodeToString(hashedBytes)
-	return hashedPassword, nil
+	key := argon2.IDKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argonPrefix, argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
+// CheckPassword сравнивает пароль с хэшем за постоянное время. Поддерживаются хэши argon2id
+// и устаревшие хэши SHA-256 с фиксированной солью
 func CheckPassword(password, passwordHash string) (bool, error) {
-	hashed, err := HashPassword(password)
+	if !strings.HasPrefix(passwordHash, argonPrefix) {
+		legacyHash := legacyHashPassword(password)
+		return subtle.ConstantTimeCompare([]byte(legacyHash), []byte(passwordHash)) == 1, nil
+	}
+
+	hash, err := parseArgonHash(passwordHash)
 	if err != nil {
 		return false, err
 	}
-	return hashed == passwordHash, nil
+
+	key := argon2.IDKey([]byte(password), hash.salt, hash.time, hash.memory, hash.threads, uint32(len(hash.key)))
+	return subtle.ConstantTimeCompare(key, hash.key) == 1, nil
+}
+
+// NeedsRehash проверяет, что хэш пароля получен устаревшим алгоритмом или с другими параметрами
+// и его нужно заменить при следующем успешном входе
+func NeedsRehash(passwordHash string) bool {
+	hash, err := parseArgonHash(passwordHash)
+	if err != nil {
+		return true
+	}
+	return hash.memory != argonMemory || hash.time != argonTime || hash.threads != argonThreads ||
+		len(hash.salt) != argonSaltLen || len(hash.key) != int(argonKeyLen)
+}
+
+// parseArgonHash разбирает хэш argon2id в формате PHC
+func parseArgonHash(passwordHash string) (*argonHash, error) {
+	parts := strings.Split(passwordHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, ErrInvalidHash
+	}
+
+	var hash argonHash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &hash.memory, &hash.time, &hash.threads); err != nil {
+		return nil, ErrInvalidHash
+	}
+
+	var err error
+	if hash.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, ErrInvalidHash
+	}
+	if hash.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(hash.key) == 0 {
+		return nil, ErrInvalidHash
+	}
+	return &hash, nil
+}
+
+// legacyHashPassword хэширует пароль устаревшим алгоритмом SHA-256 с фиксированной солью.
+// Используется только для проверки паролей пользователей, зарегистрированных до перехода на argon2id
+func legacyHashPassword(password string) string {
+	salt := "|3%$cris2QJlfs|R"
+
+	hashedBytes := sha256.Sum256([]byte(password + salt))
+	return hex.EncodeToString(hashedBytes[:])
 }
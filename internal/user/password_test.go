@@ -0,0 +1,81 @@
+package user_test
+
+import (
+	"marketplace/internal/user"
+	"strings"
+	"testing"
+)
+
+// TestHashPassword тестирует хэширование пароля алгоритмом argon2id и проверку пароля по хэшу
+func TestHashPassword(t *testing.T) {
+	password := "&N^_?e9G!m+B>[k3a"
+	first, err := user.HashPassword(password)
+	if err != nil {
+		t.Fatalf("Ошибка хэширования пароля: %v", err)
+	}
+	second, err := user.HashPassword(password)
+	if err != nil {
+		t.Fatalf("Ошибка хэширования пароля: %v", err)
+	}
+
+	if !strings.HasPrefix(first, "$argon2id$v=19$") {
+		t.Errorf("Ожидался хэш argon2id в формате PHC, но получен: %s", first)
+	}
+	if first == second {
+		t.Error("Хэши одного пароля с разной солью не должны совпадать")
+	}
+	if user.NeedsRehash(first) {
+		t.Error("Хэш с текущими параметрами не должен требовать повторного хэширования")
+	}
+
+	tests := []struct {
+		password, hash string
+		result         bool
+	}{
+		{password, first, true},
+		{password, second, true},
+		{"&N^_?e9G!m+B>[k3b", first, false},
+		{"", first, false},
+	}
+	for _, test := range tests {
+		check, err := user.CheckPassword(test.password, test.hash)
+		if err != nil {
+			t.Fatalf("Ошибка проверки пароля: %v", err)
+		}
+		if check != test.result {
+			t.Errorf("%q: ожидалось %t, но получено %t", test.password, test.result, check)
+		}
+	}
+}
+
+// TestCheckLegacyPassword тестирует проверку пароля по устаревшему хэшу SHA-256
+func TestCheckLegacyPassword(t *testing.T) {
+	legacyHash := "b2749ac834482a3b029a88080c3ded8072d2232505daf80617d3fef7c28935e9"
+
+	check, err := user.CheckPassword("W#_?e9o!m+B>tk7j", legacyHash)
+	if err != nil || !check {
+		t.Errorf("Ожидалось совпадение пароля с устаревшим хэшем, но получено: %t, %v", check, err)
+	}
+	check, err = user.CheckPassword("W#_?e9o!m+B>tk7J", legacyHash)
+	if err != nil || check {
+		t.Errorf("Ожидалось несовпадение неверного пароля с устаревшим хэшем, но получено: %t, %v", check, err)
+	}
+	if !user.NeedsRehash(legacyHash) {
+		t.Error("Устаревший хэш должен требовать повторного хэширования")
+	}
+}
+
+// TestCheckInvalidHash тестирует проверку пароля по поврежденному хэшу argon2id
+func TestCheckInvalidHash(t *testing.T) {
+	hashes := []string{
+		"$argon2id$v=19$m=65536,t=1,p=4$c2FsdA",
+		"$argon2id$v=18$m=65536,t=1,p=4$c2FsdA$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=4$c2FsdA$a2V5",
+		"$argon2id$v=19$m=65536,t=1,p=4$!!!$a2V5",
+	}
+	for _, hash := range hashes {
+		if _, err := user.CheckPassword("password", hash); err == nil {
+			t.Errorf("Ожидалась ошибка для хэша %q", hash)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"marketplace/internal/cards"
+	hdr "marketplace/internal/handlers"
+	"strings"
+)
+
+// avatarURLFormat — формат адреса изображения аватара
+const avatarURLFormat string = "/images/%s.jpeg"
+
+// GetProfile получает публичный профиль пользователя
+func (repo *UserDBRepository) GetProfile(username string) (*Profile, int, error) {
+	query := fmt.Sprintf(`
+    SELECT u.username, COALESCE(u.display_name, u.username), u.avatar_image, u.bio, u.created_at,
+           (SELECT COUNT(*) FROM cards c WHERE c.user_id = u.id AND c.status = '%s' AND c.expires_at > NOW()),
+           (SELECT ROUND(AVG(r.rating), 2) FROM reviews r WHERE r.seller_id = u.id),
+           (SELECT COUNT(*) FROM reviews r WHERE r.seller_id = u.id),
+           (SELECT COUNT(*) FROM follows f WHERE f.seller_id = u.id),
+           (SELECT COUNT(*) FROM follows f WHERE f.follower_id = u.id)
+    FROM users u
+    WHERE u.username = $1;`, cards.StatusPublished)
+
+	var profile Profile
+	var avatarImage sql.NullString
+	err := repo.dtb.QueryRow(query, username).Scan(&profile.Username, &profile.DisplayName, &avatarImage, &profile.Bio,
+		&profile.RegisteredAt, &profile.ActiveCardsCount, &profile.Rating, &profile.ReviewsCount,
+		&profile.FollowersCount, &profile.FollowingCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, hdr.NotFoundCode, fmt.Errorf("пользователь не существует")
+	}
+	if err != nil {
+		return nil, hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: получение профиля пользователя: %v", err)
+	}
+
+	if avatarImage.Valid {
+		avatarURL := fmt.Sprintf(avatarURLFormat, avatarImage.String)
+		profile.AvatarURL = &avatarURL
+	}
+	return &profile, hdr.OKCode, nil
+}
+
+// UpdateProfile изменяет профиль пользователя. Аватаром может быть только изображение,
+// загруженное этим же пользователем
+func (repo *UserDBRepository) UpdateProfile(userID string, upd *ProfileUpdate) (int, error) {
+	var setClauses []string
+	var args []interface{}
+	argPos := 1
+
+	if upd.DisplayName != nil {
+		setClauses = append(setClauses, fmt.Sprintf("display_name = NULLIF($%d, '')", argPos))
+		args = append(args, *upd.DisplayName)
+		argPos++
+	}
+	if upd.Bio != nil {
+		setClauses = append(setClauses, fmt.Sprintf("bio = $%d", argPos))
+		args = append(args, *upd.Bio)
+		argPos++
+	}
+	if upd.AvatarImage != nil {
+		if *upd.AvatarImage != "" {
+			var exists bool
+			query := "SELECT EXISTS(SELECT 1 FROM images WHERE name = $1 AND user_id = $2);"
+			err := repo.dtb.QueryRow(query, *upd.AvatarImage, userID).Scan(&exists)
+			if err != nil {
+				return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: проверка изображения аватара: %v", err)
+			}
+			if !exists {
+				return hdr.BadRequestCode, fmt.Errorf("ошибка: изображение аватара не существует или загружено другим пользователем")
+			}
+		}
+		setClauses = append(setClauses, fmt.Sprintf("avatar_image = NULLIF($%d, '')", argPos))
+		args = append(args, *upd.AvatarImage)
+		argPos++
+	}
+
+	if len(setClauses) == 0 {
+		return hdr.OKCode, nil
+	}
+
+	query := fmt.Sprintf("UPDATE users SET %s WHERE id = $%d;", strings.Join(setClauses, ", "), argPos)
+	args = append(args, userID)
+	_, err := repo.dtb.Exec(query, args...)
+	if err != nil {
+		return hdr.InternalServerErrorCode, fmt.Errorf("ошибка запроса к базе данных: изменение профиля пользователя: %v", err)
+	}
+	return hdr.OKCode, nil
+}
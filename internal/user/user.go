@@ -1,10 +1,57 @@
 package user
 
+import "time"
+
 type User struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
 
+// Profile — публичный профиль пользователя
+type Profile struct {
+	// Username — логин пользователя
+	Username string `json:"username"`
+	// DisplayName — отображаемое имя; если не задано, совпадает с логином
+	DisplayName string `json:"display_name"`
+	// AvatarURL — адрес изображения аватара
+	AvatarURL *string `json:"avatar_url"`
+	// Bio — информация о себе
+	Bio string `json:"bio"`
+	// RegisteredAt — дата регистрации
+	RegisteredAt time.Time `json:"registered_at"`
+	// ActiveCardsCount — количество активных объявлений
+	ActiveCardsCount int `json:"active_cards_count"`
+	// Rating — средняя оценка продавца, nil при отсутствии отзывов
+	Rating *float64 `json:"rating"`
+	// ReviewsCount — количество отзывов о продавце
+	ReviewsCount int `json:"reviews_count"`
+	// FollowersCount — количество подписчиков
+	FollowersCount int `json:"followers_count"`
+	// FollowingCount — количество продавцов, на которых подписан пользователь
+	FollowingCount int `json:"following_count"`
+}
+
+// Follow — пользователь в списке подписчиков или подписок
+type Follow struct {
+	// Username — логин пользователя
+	Username string `json:"username"`
+	// DisplayName — отображаемое имя
+	DisplayName string `json:"display_name"`
+	// FollowedAt — дата подписки
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// ProfileUpdate содержит изменяемые поля профиля. Поля, равные nil, не изменяются,
+// пустая строка сбрасывает значение поля
+type ProfileUpdate struct {
+	// DisplayName — отображаемое имя
+	DisplayName *string
+	// Bio — информация о себе
+	Bio *string
+	// AvatarImage — имя загруженного пользователем изображения аватара
+	AvatarImage *string
+}
+
 type UserRepo interface {
 	// GetUserID получает идентификатор пользователя
 	GetUserID(username string) (string, error)
@@ -12,4 +59,16 @@ type UserRepo interface {
 	SignIn(usr *User) (*User, int, error)
 	// SignUp регистрирует нового пользователя
 	SignUp(usr *User) (*User, int, error)
+	// GetProfile получает публичный профиль пользователя
+	GetProfile(username string) (*Profile, int, error)
+	// UpdateProfile изменяет профиль пользователя
+	UpdateProfile(userID string, upd *ProfileUpdate) (int, error)
+	// Follow подписывает пользователя на продавца
+	Follow(followerID, seller string) (int, error)
+	// Unfollow отменяет подписку пользователя на продавца
+	Unfollow(followerID, seller string) (int, error)
+	// GetFollowers получает подписчиков пользователя
+	GetFollowers(username string, limit, offset int) ([]Follow, int, error)
+	// GetFollowing получает продавцов, на которых подписан пользователь
+	GetFollowing(username string, limit, offset int) ([]Follow, int, error)
 }
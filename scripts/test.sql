@@ -3,11 +3,73 @@ CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 CREATE TABLE users (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     username TEXT UNIQUE NOT NULL,
-    password_hash TEXT NOT NULL
+    password_hash TEXT NOT NULL,
+    -- отображаемое имя
+    display_name TEXT,
+    -- информация о себе
+    bio TEXT NOT NULL DEFAULT '',
+    -- имя изображения аватара
+    avatar_image TEXT,
+    -- дата регистрации
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
 INSERT INTO users (username, password_hash) VALUES ('user1', 'b2749ac834482a3b029a88080c3ded8072d2232505daf80617d3fef7c28935e9');
 
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- владелец сессии
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- устройство (заголовок User-Agent)
+    user_agent TEXT NOT NULL DEFAULT '',
+    -- IP-адрес, с которого выполнен вход
+    ip TEXT NOT NULL DEFAULT '',
+    -- дата входа
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- дата последнего использования
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- дата завершения сессии
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+CREATE TABLE refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- сессия, к семейству токенов которой относится refresh-токен
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    -- хэш SHA-256 refresh-токена
+    token_hash TEXT UNIQUE NOT NULL,
+    -- дата выдачи
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- дата истечения срока действия
+    expires_at TIMESTAMP NOT NULL,
+    -- дата обмена на новый refresh-токен
+    used_at TIMESTAMP
+);
+
+CREATE INDEX refresh_tokens_session_id_idx ON refresh_tokens (session_id);
+
+CREATE TABLE categories (
+    id SERIAL PRIMARY KEY,
+    -- название категории
+    name TEXT NOT NULL,
+    -- родительская категория
+    parent_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    UNIQUE (parent_id, name)
+);
+
+INSERT INTO categories (id, name, parent_id) VALUES
+    (1, 'Электроника', NULL),
+    (2, 'Телефоны', 1),
+    (3, 'Ноутбуки', 1),
+    (4, 'Одежда', NULL),
+    (5, 'Дом и сад', NULL),
+    (6, 'Мебель', 5),
+    (7, 'Прочее', NULL);
+
+SELECT setval('categories_id_seq', (SELECT MAX(id) FROM categories));
+
 CREATE TABLE cards (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     -- заголовок
@@ -20,10 +82,228 @@ CREATE TABLE cards (
     price NUMERIC NOT NULL,
     -- автор
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- категория
+    category_id INTEGER NOT NULL REFERENCES categories(id),
+    -- статус объявления
+    status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('draft', 'published', 'reserved', 'sold', 'archived')),
+    -- причина архивирования: expired — истек срок жизни, manual — архивировано автором
+    archived_reason TEXT CHECK (archived_reason IN ('expired', 'manual')),
+    -- дата создания
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- дата истечения срока жизни объявления
+    expires_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP + INTERVAL '30 days',
+    -- транзакция, в которой объявление было опубликовано последний раз (поток новых объявлений)
+    published_xid XID8,
+    -- поисковый вектор по заголовку и тексту объявления (русская и английская конфигурации)
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', title), 'A') ||
+        setweight(to_tsvector('english', title), 'A') ||
+        setweight(to_tsvector('russian', card_text), 'B') ||
+        setweight(to_tsvector('english', card_text), 'B')
+    ) STORED
+);
+
+CREATE INDEX cards_status_idx ON cards (status);
+CREATE INDEX cards_category_id_idx ON cards (category_id);
+CREATE INDEX cards_expires_at_idx ON cards (expires_at);
+CREATE INDEX cards_search_vector_idx ON cards USING GIN (search_vector);
+CREATE INDEX cards_published_xid_idx ON cards (published_xid, id) WHERE status = 'published';
+
+-- set_published_xid запоминает транзакцию, в которой объявление перешло в статус published
+CREATE FUNCTION set_published_xid() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.status = 'published' AND (TG_OP = 'INSERT' OR OLD.status <> 'published') THEN
+        NEW.published_xid := pg_current_xact_id();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER cards_published_xid BEFORE INSERT OR UPDATE OF status ON cards
+    FOR EACH ROW EXECUTE FUNCTION set_published_xid();
+
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- название тега
+    name TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE card_tags (
+    card_id UUID NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (card_id, tag_id)
+);
+
+CREATE INDEX card_tags_tag_id_idx ON card_tags (tag_id);
+
+CREATE TABLE favorites (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    card_id UUID NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    -- дата добавления в избранное
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, card_id)
+);
+
+CREATE INDEX favorites_card_id_idx ON favorites (card_id);
+
+CREATE TABLE follows (
+    -- подписчик
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- продавец, на которого оформлена подписка
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- дата подписки
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, seller_id),
+    CHECK (follower_id <> seller_id)
+);
+
+CREATE INDEX follows_seller_id_idx ON follows (seller_id, created_at DESC);
+
+CREATE TABLE saved_searches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- владелец
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- название
+    name TEXT NOT NULL,
+    -- фильтры ленты объявлений
+    filter JSONB NOT NULL,
     -- дата создания
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX saved_searches_user_id_idx ON saved_searches (user_id);
+
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- получатель
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- вид уведомления
+    kind TEXT NOT NULL,
+    -- объявление
+    card_id UUID REFERENCES cards(id) ON DELETE CASCADE,
+    -- сохраненный поиск
+    search_id UUID REFERENCES saved_searches(id) ON DELETE CASCADE,
+    -- признак прочтения
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    -- дата создания
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, created_at DESC);
+CREATE UNIQUE INDEX notifications_search_card_idx ON notifications (search_id, card_id) WHERE search_id IS NOT NULL;
+
+CREATE TABLE conversations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- объявление
+    card_id UUID NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    -- покупатель
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- продавец
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- дата создания
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- дата последнего сообщения
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (card_id, buyer_id, seller_id)
+);
+
+CREATE INDEX conversations_buyer_id_idx ON conversations (buyer_id, updated_at DESC);
+CREATE INDEX conversations_seller_id_idx ON conversations (seller_id, updated_at DESC);
+
+CREATE TABLE messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    -- отправитель
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- текст сообщения
+    body TEXT NOT NULL,
+    -- признак прочтения получателем
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    -- дата отправки
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX messages_conversation_id_idx ON messages (conversation_id, created_at DESC);
+
+CREATE TABLE offers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- объявление
+    card_id UUID NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    -- покупатель
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- продавец
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- автор предложения: покупатель или продавец (встречное предложение)
+    proposed_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- предложение, в ответ на которое сделано встречное предложение
+    parent_id UUID REFERENCES offers(id) ON DELETE CASCADE,
+    -- предложенная цена
+    amount NUMERIC NOT NULL CHECK (amount > 0),
+    -- статус предложения
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'ordered', 'rejected', 'countered', 'expired', 'cancelled')),
+    -- дата создания
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- дата истечения срока действия предложения
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX offers_card_id_idx ON offers (card_id, created_at DESC);
+CREATE INDEX offers_buyer_id_idx ON offers (buyer_id, created_at DESC);
+CREATE INDEX offers_seller_id_idx ON offers (seller_id, created_at DESC);
+CREATE UNIQUE INDEX offers_pending_idx ON offers (card_id, buyer_id) WHERE status = 'pending';
+
+CREATE TABLE orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- объявление: объявление с заказами нельзя удалить
+    card_id UUID NOT NULL REFERENCES cards(id) ON DELETE RESTRICT,
+    -- принятое предложение цены, по которому создан заказ
+    offer_id UUID REFERENCES offers(id) ON DELETE SET NULL,
+    -- покупатель
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- продавец
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- сумма заказа
+    amount NUMERIC NOT NULL CHECK (amount > 0),
+    -- статус заказа
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'shipped', 'completed', 'cancelled')),
+    -- идентификатор платежа у платежного провайдера
+    payment_id TEXT UNIQUE,
+    -- адрес страницы оплаты
+    payment_url TEXT,
+    -- возврат платежа отмененного заказа: pending — ожидает возврата, refunded — возвращен
+    refund_status TEXT CHECK (refund_status IN ('pending', 'refunded')),
+    -- дата создания
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- дата последнего изменения статуса
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- срок оплаты, после которого неоплаченный заказ отменяется
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX orders_buyer_id_idx ON orders (buyer_id, created_at DESC);
+CREATE INDEX orders_seller_id_idx ON orders (seller_id, created_at DESC);
+CREATE UNIQUE INDEX orders_active_card_idx ON orders (card_id) WHERE status IN ('pending', 'paid', 'shipped');
+CREATE INDEX orders_pending_expires_at_idx ON orders (expires_at) WHERE status = 'pending';
+CREATE INDEX orders_pending_refund_idx ON orders (updated_at) WHERE refund_status = 'pending';
+
+CREATE TABLE reviews (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- завершенный заказ, по которому оставлен отзыв: отзыв нельзя удалить вместе с заказом
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE RESTRICT,
+    -- автор отзыва (покупатель)
+    reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- продавец
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- оценка
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    -- текст отзыва
+    review_text TEXT NOT NULL,
+    -- дата создания
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX reviews_seller_id_idx ON reviews (seller_id, created_at DESC);
+
 CREATE TABLE images (
   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),     
   name TEXT UNIQUE NOT NULL,     
@@ -33,4 +313,7 @@ CREATE TABLE images (
   -- автор
   user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
   uploaded_at TIMESTAMP   NOT NULL DEFAULT NOW()
-);
\ No newline at end of file
+);
+
+ALTER TABLE users ADD CONSTRAINT users_avatar_image_fkey
+    FOREIGN KEY (avatar_image) REFERENCES images(name) ON DELETE SET NULL;
\ No newline at end of file